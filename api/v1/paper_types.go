@@ -20,29 +20,868 @@ import (
 	"fmt"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// UpdatePolicy defines how the build, and possibly the version, to run is selected
+// +kubebuilder:validation:Enum=Pinned;LatestBuild;LatestPatch;LatestRelease
+type UpdatePolicy string
+
+const (
+	// UpdatePolicyPinned runs exactly the given version and build.
+	UpdatePolicyPinned UpdatePolicy = "Pinned"
+	// UpdatePolicyLatestBuild runs the latest build of the given version.
+	UpdatePolicyLatestBuild UpdatePolicy = "LatestBuild"
+	// UpdatePolicyLatestPatch runs the latest build of the latest patch release within the minor of the given version.
+	UpdatePolicyLatestPatch UpdatePolicy = "LatestPatch"
+	// UpdatePolicyLatestRelease runs the latest build of the latest release.
+	UpdatePolicyLatestRelease UpdatePolicy = "LatestRelease"
+)
+
+// Channel is a release channel of Paper builds
+// +kubebuilder:validation:Enum=default;experimental
+type Channel string
+
+const (
+	// ChannelDefault only accepts builds considered stable.
+	ChannelDefault Channel = "default"
+	// ChannelExperimental accepts builds of any channel.
+	ChannelExperimental Channel = "experimental"
+)
+
+// Workload defines how the server is run
+// +kubebuilder:validation:Enum=Pod;StatefulSet
+type Workload string
+
+const (
+	// WorkloadPod runs the server in a Pod managed by the operator, which recreates it once it failed or is gone.
+	WorkloadPod Workload = "Pod"
+	// WorkloadStatefulSet runs the server in a StatefulSet of a single replica, which is rescheduled by Kubernetes,
+	// e.g. after evictions. The world volume is claimed by the StatefulSet.
+	WorkloadStatefulSet Workload = "StatefulSet"
+)
+
 // PaperSpec defines the desired state of Paper
+// +kubebuilder:validation:XValidation:rule="!has(self.build) || !has(self.updatePolicy) || self.updatePolicy == 'Pinned'",message="build requires updatePolicy Pinned"
+// +kubebuilder:validation:XValidation:rule="has(self.build) || !has(self.updatePolicy) || self.updatePolicy != 'Pinned'",message="updatePolicy Pinned requires build"
+// +kubebuilder:validation:XValidation:rule="(has(self.workload) ? self.workload : 'Pod') == (has(oldSelf.workload) ? oldSelf.workload : 'Pod')",message="workload is immutable"
 type PaperSpec struct {
+	// Version is the Minecraft version to run, e.g. 1.20 or 1.20.4. With updatePolicy LatestPatch only its minor
+	// version is read, i.e. both 1.20 and 1.20.4 select the latest release within 1.20.x. With LatestRelease the latest
+	// release is run regardless.
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Pattern=\d+.\d+.\d+
+	// +kubebuilder:validation:Pattern=`^\d+\.\d+(\.\d+)?$`
 	Version string `json:"version"`
+
+	// Build pins the build of the version to run.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Build *int `json:"build,omitempty"`
+
+	// UpdatePolicy defines how the build to run is selected. Defaults to Pinned if a build is given, LatestBuild
+	// otherwise.
+	// +optional
+	UpdatePolicy UpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// Channel is the least stable release channel builds are taken from. Defaults to experimental, i.e. any build.
+	// Pinned builds are not subject to the channel.
+	// +optional
+	Channel Channel `json:"channel,omitempty"`
+
+	// Eula records the acceptance of the Minecraft EULA, the server is not started without it. Paper resources created
+	// before it was introduced lack it, their running server is left alone, but they are not reconciled any further
+	// until spec.eula.accepted is set.
+	// +kubebuilder:validation:Required
+	Eula EulaSpec `json:"eula"`
+
+	// ServerProperties are rendered into server.properties of the instance. If unset, server.properties on the
+	// world volume is left to the server.
+	// +optional
+	ServerProperties *ServerProperties `json:"serverProperties,omitempty"`
+
+	// PaperConfig is merged onto the configuration files of Paper, Bukkit and Spigot on the world volume.
+	// +optional
+	PaperConfig *PaperConfigSpec `json:"paperConfig,omitempty"`
+
+	// Resources of the server container. The memory limit determines the heap size of the JVM.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Jvm configures the JVM and the arguments of the server.
+	// +optional
+	Jvm *JvmSpec `json:"jvm,omitempty"`
+
+	// Workload defines how the server is run. Defaults to Pod. It cannot be changed, the world volume is claimed
+	// differently.
+	// +optional
+	Workload Workload `json:"workload,omitempty"`
+
+	// Storage configures the volumes of the instance.
+	// +optional
+	Storage *StorageSpec `json:"storage,omitempty"`
+
+	// Service configures the Service exposing the server.
+	// +optional
+	Service *ServiceSpec `json:"service,omitempty"`
+
+	// Api overrides the PaperMC API used by the operator for this instance, e.g. to use a mirror.
+	// +optional
+	Api *ApiSpec `json:"api,omitempty"`
+
+	// Probes configure how the health of the server is probed.
+	// +optional
+	Probes *ProbesSpec `json:"probes,omitempty"`
+
+	// Images overrides the images run for the instance, e.g. to pull from an internal registry.
+	// +optional
+	Images *ImagesSpec `json:"images,omitempty"`
+
+	// PodTemplate is merged onto the Pods created for the instance, e.g. to schedule them onto dedicated nodes.
+	// +optional
+	PodTemplate *PodTemplateSpec `json:"podTemplate,omitempty"`
+
+	// Shutdown configures how the server is stopped before its Pod is replaced, e.g. on upgrades.
+	// +optional
+	Shutdown *ShutdownSpec `json:"shutdown,omitempty"`
+
+	// Access declares the operators, the whitelist and the bans of the server. Changes are applied by console
+	// commands while the server is running where possible, otherwise the server is restarted.
+	// +optional
+	Access *AccessSpec `json:"access,omitempty"`
+
+	// Plugins are downloaded and verified into a volume per set of plugins, and copied into the plugins directory of
+	// the server on start. Plugins removed from the list are removed from the plugins directory, their data is kept.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Plugins []PluginSpec `json:"plugins,omitempty"`
+
+	// ConfigFiles are copied into the data directory on start, e.g. configurations of plugins. The server may still
+	// write to them, they are replaced once their content changes.
+	// +listType=map
+	// +listMapKey=path
+	// +optional
+	ConfigFiles []ConfigFileSpec `json:"configFiles,omitempty"`
+
+	// Backup archives the data directory of the server on a schedule, while the server is running.
+	// +optional
+	Backup *BackupSpec `json:"backup,omitempty"`
+}
+
+// EulaSpec defines the acceptance of the Minecraft EULA
+type EulaSpec struct {
+	// Accepted acknowledges the Minecraft EULA (https://aka.ms/MinecraftEULA), it must be true.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:XValidation:rule="self == true",message="the Minecraft EULA (https://aka.ms/MinecraftEULA) must be accepted"
+	Accepted bool `json:"accepted"`
+}
+
+// Difficulty is the difficulty of a server
+// +kubebuilder:validation:Enum=peaceful;easy;normal;hard
+type Difficulty string
+
+// GameMode is the default game mode of a server
+// +kubebuilder:validation:Enum=survival;creative;adventure;spectator
+type GameMode string
+
+// PaperConfigSpec defines settings merged onto the configuration files of the server, e.g. for performance tuning.
+// Mappings are merged recursively, any other value, including lists, replaces the one of the file. Settings not given
+// are left to the server. Changes restart the server.
+type PaperConfigSpec struct {
+	// Global is merged onto config/paper-global.yml.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Global *runtime.RawExtension `json:"global,omitempty"`
+
+	// WorldDefaults is merged onto config/paper-world-defaults.yml, e.g. entity activation ranges.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	WorldDefaults *runtime.RawExtension `json:"worldDefaults,omitempty"`
+
+	// Bukkit is merged onto bukkit.yml.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Bukkit *runtime.RawExtension `json:"bukkit,omitempty"`
+
+	// Spigot is merged onto spigot.yml.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Spigot *runtime.RawExtension `json:"spigot,omitempty"`
+}
+
+// ServerProperties defines the content of server.properties
+type ServerProperties struct {
+	// Motd is the message of the day shown in the server list.
+	// +optional
+	Motd *string `json:"motd,omitempty"`
+
+	// +optional
+	Difficulty Difficulty `json:"difficulty,omitempty"`
+
+	// +optional
+	Gamemode GameMode `json:"gamemode,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxPlayers *int32 `json:"maxPlayers,omitempty"`
+
+	// +kubebuilder:validation:Minimum=3
+	// +kubebuilder:validation:Maximum=32
+	// +optional
+	ViewDistance *int32 `json:"viewDistance,omitempty"`
+
+	// OnlineMode enables authentication of players against Mojang.
+	// +optional
+	OnlineMode *bool `json:"onlineMode,omitempty"`
+
+	// Additional holds further properties by their key in server.properties, e.g. "spawn-protection". The typed
+	// fields above take precedence.
+	// +optional
+	Additional map[string]string `json:"additional,omitempty"`
+}
+
+// JvmFlags is a preset of JVM flags
+// +kubebuilder:validation:Enum=None;Aikar
+type JvmFlags string
+
+const (
+	// JvmFlagsNone applies no flags besides heap sizing.
+	JvmFlagsNone JvmFlags = "None"
+	// JvmFlagsAikar applies the G1 tuning recommended for Paper, see https://docs.papermc.io/paper/aikars-flags.
+	JvmFlagsAikar JvmFlags = "Aikar"
+)
+
+// JvmSpec defines the JVM running the server
+type JvmSpec struct {
+	// HeapPercentage is the share of the container memory limit used for the heap. Without memory limit the JVM
+	// defaults apply. Defaults to 75.
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=95
+	// +optional
+	HeapPercentage *int32 `json:"heapPercentage,omitempty"`
+
+	// Flags selects a preset of JVM flags. Defaults to None.
+	// +optional
+	Flags JvmFlags `json:"flags,omitempty"`
+
+	// ExtraJvmArgs are passed to the JVM after the preset flags.
+	// +optional
+	ExtraJvmArgs []string `json:"extraJvmArgs,omitempty"`
+
+	// ExtraServerArgs are passed to the server, e.g. --nogui.
+	// +optional
+	ExtraServerArgs []string `json:"extraServerArgs,omitempty"`
+}
+
+// ImagesSpec defines the images run for an instance
+type ImagesSpec struct {
+	// Server is the image running the server, it must provide java in the PATH. Defaults to the image configured in the
+	// operator for the version of Java required by the version of Minecraft.
+	// +optional
+	Server string `json:"server,omitempty"`
+
+	// Downloader is the image downloading artifacts and plugins, it must provide sh, wget, sha256sum and sha512sum.
+	// Defaults to the image configured in the operator.
+	// +optional
+	Downloader string `json:"downloader,omitempty"`
+}
+
+// PodTemplateSpec defines overrides strategically merged onto the Pods of an instance. All fields but containers apply
+// to all Pods, including the ones downloading artifacts and plugins, containers apply to the Pod of the server only.
+// Changes restart the server.
+type PodTemplateSpec struct {
+	// Labels are added to the Pods, labels of the operator take precedence.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to the Pods, annotations of the operator take precedence.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// ServiceAccountName runs the Pods with the given service account, its token is not mounted.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Env is added to the server container, e.g. for agents attached by jvm.extraArgs, and to the container of the
+	// other Pods, e.g. for proxies.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Volumes are added to the Pods.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=array
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// VolumeMounts are added to the server container and to the container of the other Pods.
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// Containers are added to the Pod of the server as sidecars, a container named paper is merged onto the server
+	// container instead.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=array
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Containers []corev1.Container `json:"containers,omitempty"`
+}
+
+// StorageSpec defines the volumes of an instance
+type StorageSpec struct {
+	// World is the volume holding worlds and data of the server. Defaults to 1G.
+	// +optional
+	World *VolumeSpec `json:"world,omitempty"`
+
+	// Artifacts are the volumes holding a downloaded build each. Defaults to 50M.
+	// +optional
+	Artifacts *VolumeSpec `json:"artifacts,omitempty"`
+
+	// Plugins are the volumes holding a downloaded set of plugins each. Defaults to 200M.
+	// +optional
+	Plugins *VolumeSpec `json:"plugins,omitempty"`
+}
+
+// VolumeSpec defines a persistent volume claim
+type VolumeSpec struct {
+	// Size is the requested storage. Growing it expands existing claims if their storage class allows volume
+	// expansion, shrinking is not supported.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// StorageClassName of the claim, the default storage class is used if unset. Only applies to new claims.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// AccessModes of the claim. Defaults to ReadWriteOnce. Only applies to new claims.
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+
+	// Selector restricts the volumes considered for binding. Only applies to new claims.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ServiceSpec defines the Service exposing an instance
+type ServiceSpec struct {
+	// Type of the Service. Defaults to NodePort.
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Port the server is exposed on. Defaults to 25565.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// NodePort fixes the node port of the server, a free one is allocated otherwise. Only applies to types NodePort
+	// and LoadBalancer.
+	// +optional
+	NodePort *int32 `json:"nodePort,omitempty"`
+
+	// LoadBalancerIP requests an address from the load balancer, if supported by it.
+	// +optional
+	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
+
+	// LoadBalancerClass selects the load balancer implementation. It cannot be changed once set.
+	// +optional
+	LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
+
+	// ExternalTrafficPolicy defines the routing of external traffic. Only applies to types NodePort and LoadBalancer.
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+
+	// Annotations are added to the Service, e.g. for MetalLB or external-dns.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// ExtraPorts are exposed in addition to the server, e.g. for query or plugins like Dynmap.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ExtraPorts []ServicePort `json:"extraPorts,omitempty"`
+}
+
+// ServicePort defines an additional port of the Service
+type ServicePort struct {
+	// Name of the port, e.g. dynmap.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=15
+	Name string `json:"name"`
+
+	// Port exposed by the Service.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// TargetPort in the server container. Defaults to port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	TargetPort *int32 `json:"targetPort,omitempty"`
+
+	// Protocol of the port. Defaults to TCP.
+	// +kubebuilder:validation:Enum=TCP;UDP
+	// +optional
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+
+	// NodePort fixes the node port, a free one is allocated otherwise. Only applies to types NodePort and
+	// LoadBalancer.
+	// +optional
+	NodePort *int32 `json:"nodePort,omitempty"`
+}
+
+// ApiSpec defines the PaperMC API, or a mirror of it, used for discovering and downloading builds
+type ApiSpec struct {
+	// Url is the base URL of the API, download URLs are built from it as well. The TLS trust and auth header the
+	// operator is configured with are not used for another API than its own.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	Url string `json:"url,omitempty"`
+
+	// CaBundle references a ConfigMap key holding PEM encoded certificates trusted for requests of the operator to the
+	// API. It is not passed to the provisioner, which does not verify certificates, downloads are verified by their
+	// SHA-256 checksum published by the API instead.
+	// +optional
+	CaBundle *corev1.ConfigMapKeySelector `json:"caBundle,omitempty"`
+
+	// AuthHeader references a Secret key holding a header ("Name: value") sent with each request to the API,
+	// including the download of the provisioner.
+	// +optional
+	AuthHeader *corev1.SecretKeySelector `json:"authHeader,omitempty"`
+}
+
+// ShutdownSpec defines how the server is stopped. Players are warned, the worlds are saved and the server is stopped
+// via RCON, its Pod is deleted once it exited.
+type ShutdownSpec struct {
+	// CountdownSeconds is the time players are warned ahead of the server stopping. Defaults to 10.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=600
+	// +optional
+	CountdownSeconds *int32 `json:"countdownSeconds,omitempty"`
+
+	// Message is broadcast to players repeatedly during the countdown: every minute, at 30 and 10 seconds, and every
+	// second of the last 5 seconds. "{seconds}" is replaced by the remaining seconds. Defaults to "Server restarts in
+	// {seconds} seconds".
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// TerminationGracePeriodSeconds is the time the server is given to save the worlds and exit, both after being
+	// stopped and after its Pod is deleted. Defaults to 60.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+}
+
+// AccessSpec defines who may join and administrate the server. Players are given by name or UUID, the other one is
+// resolved via the Mojang API, or derived from the name if the server is not in online mode.
+type AccessSpec struct {
+	// Ops are the operators of the server, rendered into ops.json.
+	// +optional
+	Ops []OpSpec `json:"ops,omitempty"`
+
+	// Whitelist restricts the players allowed to join, rendered into whitelist.json.
+	// +optional
+	Whitelist *WhitelistSpec `json:"whitelist,omitempty"`
+
+	// BannedPlayers are not allowed to join, rendered into banned-players.json.
+	// +optional
+	BannedPlayers []BannedPlayerSpec `json:"bannedPlayers,omitempty"`
+
+	// BannedIps are not allowed to join, rendered into banned-ips.json.
+	// +optional
+	BannedIps []BannedIpSpec `json:"bannedIps,omitempty"`
+}
+
+// PlayerRef identifies a player by name or UUID
+// +kubebuilder:validation:XValidation:rule="has(self.name) || has(self.uuid)",message="name or uuid must be given"
+type PlayerRef struct {
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_]{1,16}$`
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// UUID is given with or without dashes.
+	// +kubebuilder:validation:Pattern=`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`
+	// +optional
+	UUID string `json:"uuid,omitempty"`
+}
+
+// OpSpec defines an operator of the server
+type OpSpec struct {
+	PlayerRef `json:",inline"`
+
+	// Level is the permission level of the operator. Defaults to the op-permission-level of the server, which is 4
+	// unless given by the additional server properties. Other levels are applied by restarting the server.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4
+	// +optional
+	Level *int32 `json:"level,omitempty"`
+
+	// BypassesPlayerLimit allows the operator to join a full server. It is applied by restarting the server.
+	// +optional
+	BypassesPlayerLimit bool `json:"bypassesPlayerLimit,omitempty"`
+}
+
+// WhitelistSpec defines the whitelist of the server
+type WhitelistSpec struct {
+	// Enabled restricts joining to the players of the whitelist, rendered into white-list of server.properties.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Enforced kicks players not on the whitelist once it is reloaded, rendered into enforce-whitelist of
+	// server.properties.
+	// +optional
+	Enforced bool `json:"enforced,omitempty"`
+
+	// +optional
+	Players []PlayerRef `json:"players,omitempty"`
+}
+
+// BannedPlayerSpec defines a banned player
+type BannedPlayerSpec struct {
+	PlayerRef `json:",inline"`
+
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Expires lifts the ban at the given time, it is applied by restarting the server. Bans are permanent by default.
+	// +optional
+	Expires *metav1.Time `json:"expires,omitempty"`
+}
+
+// BannedIpSpec defines a banned IP address
+type BannedIpSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	Ip string `json:"ip"`
+
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Expires lifts the ban at the given time, it is applied by restarting the server. Bans are permanent by default.
+	// +optional
+	Expires *metav1.Time `json:"expires,omitempty"`
+}
+
+// PluginSpec defines a plugin and where to get it from, exactly one source must be given
+// +kubebuilder:validation:XValidation:rule="[has(self.url), has(self.hangar), has(self.modrinth), has(self.configMap)].filter(x, x).size() == 1",message="exactly one of url, hangar, modrinth and configMap must be given"
+type PluginSpec struct {
+	// Name identifies the plugin, it is installed as <name>.jar.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9][A-Za-z0-9._-]{0,62}$`
+	Name string `json:"name"`
+
+	// Url downloads the plugin from a URL, verified by the given checksum.
+	// +optional
+	Url *UrlPluginSource `json:"url,omitempty"`
+
+	// Hangar downloads the plugin from Hangar, the plugin repository of PaperMC.
+	// +optional
+	Hangar *RepositoryPluginSource `json:"hangar,omitempty"`
+
+	// Modrinth downloads the plugin from Modrinth.
+	// +optional
+	Modrinth *RepositoryPluginSource `json:"modrinth,omitempty"`
+
+	// ConfigMap takes the plugin from a key of a ConfigMap in the same namespace, usually binary data. ConfigMaps are
+	// limited to 1M.
+	// +optional
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+}
+
+// UrlPluginSource defines a plugin downloaded from a URL
+type UrlPluginSource struct {
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Url string `json:"url"`
+
+	// Sha256 is the checksum of the plugin, the download fails if it does not match.
+	// +kubebuilder:validation:Pattern=`^[0-9a-fA-F]{64}$`
+	Sha256 string `json:"sha256"`
+}
+
+// RepositoryPluginSource defines a plugin downloaded from a plugin repository
+type RepositoryPluginSource struct {
+	// Project is the slug or id of the project in the repository.
+	// +kubebuilder:validation:MinLength=1
+	Project string `json:"project"`
+
+	// Version of the project. Defaults to the latest release, which is checked for updates periodically; Modrinth
+	// only considers releases compatible with the version of the server.
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+// ConfigFileSpec defines a file in the data directory, exactly one source must be given
+// +kubebuilder:validation:XValidation:rule="has(self.configMap) != has(self.secret)",message="exactly one of configMap and secret must be given"
+type ConfigFileSpec struct {
+	// Path of the file relative to the data directory, e.g. plugins/LuckPerms/config.yml.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-][A-Za-z0-9._-]*(/[A-Za-z0-9_-][A-Za-z0-9._-]*)*$`
+	Path string `json:"path"`
+
+	// ConfigMap takes the content from a key of a ConfigMap in the same namespace.
+	// +optional
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+
+	// Secret takes the content from a key of a Secret in the same namespace.
+	// +optional
+	Secret *corev1.SecretKeySelector `json:"secret,omitempty"`
+
+	// SecretRefs are the Secrets in the same namespace the template may insert values of, other Secrets cannot be
+	// read.
+	// +optional
+	SecretRefs []corev1.LocalObjectReference `json:"secretRefs,omitempty"`
+
+	// Template renders the content as Go template, values of the Secrets given by SecretRefs are inserted by
+	// {{ secret "name" "key" }}. Values are inserted as they are, {{ secret "name" "key" | quote }} inserts them as
+	// double-quoted string escaped for YAML and JSON files. Rendered files are kept in a Secret managed by the operator.
+	// +optional
+	Template bool `json:"template,omitempty"`
+
+	// ReloadCommand is run via RCON once the content changed, e.g. "lp reloadconfig", instead of restarting the
+	// server. The file is replaced right before.
+	// +optional
+	ReloadCommand string `json:"reloadCommand,omitempty"`
+}
+
+// BackupSpec defines backups of the data directory, exactly one target must be given
+// +kubebuilder:validation:XValidation:rule="has(self.persistentVolumeClaim) != has(self.s3)",message="exactly one of persistentVolumeClaim and s3 must be given"
+type BackupSpec struct {
+	// Schedule in cron format, e.g. "0 4 * * *". A backup not started within 15 minutes of its time is skipped, one not
+	// completed within 3 hours is given up on, both to not hold back the following ones.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// TimeZone of the schedule, e.g. "Europe/Vienna". Defaults to the time zone of the cluster.
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+
+	// Suspend stops scheduling backups, backups already started are not affected.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Retention is the number of backups kept in the target, older ones are deleted after each backup. Defaults to 7.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retention *int32 `json:"retention,omitempty"`
+
+	// PersistentVolumeClaim stores the backups on an existing claim in the same namespace. It is mounted on the node
+	// of the server, its access modes must allow that.
+	// +optional
+	PersistentVolumeClaim *BackupVolumeTarget `json:"persistentVolumeClaim,omitempty"`
+
+	// S3 uploads the backups to S3 or compatible storage, e.g. MinIO.
+	// +optional
+	S3 *BackupS3Target `json:"s3,omitempty"`
+}
+
+// BackupVolumeTarget defines a claim backups are stored on
+type BackupVolumeTarget struct {
+	// ClaimName of the claim.
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// Path of the directory within the volume, defaults to its root.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-][A-Za-z0-9._-]*(/[A-Za-z0-9_-][A-Za-z0-9._-]*)*$`
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// BackupS3Target defines a bucket backups are uploaded to. Archives are uploaded in a single request, which AWS limits
+// to 5GB.
+type BackupS3Target struct {
+	// Endpoint of the storage, e.g. https://s3.eu-central-1.amazonaws.com or http://minio.minio:9000. The bucket is
+	// addressed path-style.
+	// +kubebuilder:validation:Pattern=`^https?://[^/]+/?$`
+	Endpoint string `json:"endpoint"`
+
+	// Bucket the backups are uploaded to.
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// Prefix of the keys of the backups, e.g. "minecraft/".
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Region the requests are signed for. Defaults to us-east-1.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// AccessKeyId is taken from a key of a Secret in the same namespace.
+	AccessKeyId corev1.SecretKeySelector `json:"accessKeyId"`
+
+	// SecretAccessKey is taken from a key of a Secret in the same namespace.
+	SecretAccessKey corev1.SecretKeySelector `json:"secretAccessKey"`
+}
+
+// ProbeType selects how the server is probed
+// +kubebuilder:validation:Enum=TCP;Status
+type ProbeType string
+
+const (
+	// ProbeTypeTCP probes whether the server port accepts connections, which it does before worlds are loaded.
+	ProbeTypeTCP ProbeType = "TCP"
+	// ProbeTypeStatus probes whether the server answers a server list ping, using a helper copied into the Pod.
+	ProbeTypeStatus ProbeType = "Status"
+)
+
+// ProbesSpec defines the probes of the server container
+type ProbesSpec struct {
+	// Type selects how the server is probed. Defaults to TCP.
+	// +optional
+	Type ProbeType `json:"type,omitempty"`
+
+	// Startup overrides the timings of the startup probe, defaults are an initial delay of 15s, a period of 5s and a
+	// failure threshold of 60. Big worlds may take longer to load.
+	// +optional
+	Startup *ProbeTimings `json:"startup,omitempty"`
+
+	// Readiness overrides the timings of the readiness probe, defaults are a period of 3s and a failure threshold of 1.
+	// +optional
+	Readiness *ProbeTimings `json:"readiness,omitempty"`
+
+	// Liveness overrides the timings of the liveness probe, defaults are a period of 5s and a failure threshold of 3.
+	// +optional
+	Liveness *ProbeTimings `json:"liveness,omitempty"`
+}
+
+// ProbeTimings defines the timings of a probe, see the corresponding fields of a Kubernetes probe
+type ProbeTimings struct {
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
 }
 
 // PaperStatus defines the observed state of Paper
 type PaperStatus struct {
+	// Conditions are Available, Progressing, Degraded, ArtifactReady, Upgrading and EulaAccepted.
 	Conditions       []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 	DesiredState     *DesiredState      `json:"desiredState,omitempty"`
 	ActualState      *ActualState       `json:"actualState,omitempty"`
 	UpdatedTimestamp *metav1.Time       `json:"updatedTimestamp,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the status was last reconciled for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Instance reports the state of the server Pod.
+	Instance *InstanceStatus `json:"instance,omitempty"`
+	// Server reports the state of the server as answered to a server list ping.
+	Server *ServerStatus `json:"server,omitempty"`
+
+	// DriftCorrections counts how often owned objects were modified by someone else and restored by the operator.
+	DriftCorrections int64 `json:"driftCorrections,omitempty"`
+	// LastDriftCorrectionTimestamp is the time an owned object was last restored by the operator.
+	LastDriftCorrectionTimestamp *metav1.Time `json:"lastDriftCorrectionTimestamp,omitempty"`
+
+	// Plugins reports the resolved plugins and whether they are downloaded.
+	Plugins *PluginsStatus `json:"plugins,omitempty"`
+
+	// Runtime reports the images selected for the desired version.
+	Runtime *RuntimeStatus `json:"runtime,omitempty"`
+
+	// Backup reports the last successful backup.
+	Backup *BackupStatus `json:"backup,omitempty"`
+}
+
+// BackupStatus defines the last successful backup
+type BackupStatus struct {
+	// LastBackupTimestamp is the time the last backup was completed.
+	LastBackupTimestamp *metav1.Time `json:"lastBackupTimestamp,omitempty"`
+	// LastBackupName is the name of its archive within the target.
+	LastBackupName string `json:"lastBackupName,omitempty"`
+	// LastBackupSize is the size of its archive in bytes.
+	LastBackupSize int64 `json:"lastBackupSize,omitempty"`
+}
+
+// RuntimeStatus defines the images selected for the desired version
+type RuntimeStatus struct {
+	// JavaVersion is the major version of Java required by the desired version of Minecraft.
+	JavaVersion int32 `json:"javaVersion,omitempty"`
+	// ServerImage is the image running the server.
+	ServerImage string `json:"serverImage,omitempty"`
+	// ServerImageJavaVersion is the major version of Java provided by the server image, as configured in the operator
+	// or detected by running the image.
+	ServerImageJavaVersion int32 `json:"serverImageJavaVersion,omitempty"`
+	// DownloaderImage is the image downloading artifacts and plugins.
+	DownloaderImage string `json:"downloaderImage,omitempty"`
+}
+
+// PluginsStatus defines the resolved set of plugins
+type PluginsStatus struct {
+	// Hash identifies the resolved set of plugins, it names the volume holding them.
+	Hash             string      `json:"hash,omitempty"`
+	UpdatedTimestamp metav1.Time `json:"updatedTimestamp,omitempty"`
+	// Selector records the spec the plugins were resolved for.
+	Selector string `json:"selector,omitempty"`
+
+	Items []PluginStatus `json:"items,omitempty"`
+}
+
+// PluginStatus defines the state of a plugin
+type PluginStatus struct {
+	Name string `json:"name"`
+	// Source is one of Url, Hangar, Modrinth and ConfigMap.
+	Source   string `json:"source"`
+	Version  string `json:"version,omitempty"`
+	Url      string `json:"url,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+	// Ready is true once the plugin is downloaded and verified.
+	Ready bool `json:"ready"`
+	// Message explains why the plugin is not ready.
+	Message string `json:"message,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.actualState.version.version`
+// +kubebuilder:printcolumn:name="Build",type=integer,JSONPath=`.status.actualState.version.build`
+// +kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+// +kubebuilder:printcolumn:name="Online",type=integer,JSONPath=`.status.server.onlinePlayers`
+// +kubebuilder:printcolumn:name="Max",type=integer,JSONPath=`.status.server.maxPlayers`
+// +kubebuilder:printcolumn:name="Java",type=integer,JSONPath=`.status.runtime.javaVersion`,priority=1
+// +kubebuilder:printcolumn:name="Backup",type=date,JSONPath=`.status.backup.lastBackupTimestamp`,priority=1
+// +kubebuilder:printcolumn:name="Players",type=string,JSONPath=`.status.server.players`,priority=1
+// +kubebuilder:printcolumn:name="MOTD",type=string,JSONPath=`.status.server.motd`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Paper is the Schema for the papers API
 type Paper struct {
@@ -71,13 +910,50 @@ type Version struct {
 type DesiredState struct {
 	Version          Version     `json:"version,omitempty"`
 	Url              string      `json:"url,omitempty"`
+	Sha256           string      `json:"sha256,omitempty"`
 	UpdatedTimestamp metav1.Time `json:"updatedTimestamp,omitempty"`
+
+	// Reason explains why this version and build was selected.
+	Reason string `json:"reason,omitempty"`
+	// Selector records the spec the version and build was selected for.
+	Selector string `json:"selector,omitempty"`
 }
 
 type ActualState struct {
 	Version Version `json:"version,omitempty"`
 }
 
+// ServerStatus is the state of the server as answered to a server list ping.
+type ServerStatus struct {
+	OnlinePlayers int32 `json:"onlinePlayers"`
+	MaxPlayers    int32 `json:"maxPlayers"`
+	// Players are the names of the players online, as far as reported by the server.
+	Players []string `json:"players,omitempty"`
+
+	// Version is the version name reported by the server.
+	Version  string `json:"version,omitempty"`
+	Protocol int32  `json:"protocol,omitempty"`
+	// Motd is the message of the day as plain text.
+	Motd string `json:"motd,omitempty"`
+
+	LatencyMilliseconds int64        `json:"latencyMilliseconds,omitempty"`
+	LastPingTimestamp   *metav1.Time `json:"lastPingTimestamp,omitempty"`
+}
+
+// InstanceStatus is the observed state of the server Pod.
+type InstanceStatus struct {
+	Phase        corev1.PodPhase `json:"phase,omitempty"`
+	Ready        bool            `json:"ready,omitempty"`
+	RestartCount int32           `json:"restartCount,omitempty"`
+
+	// LastTerminationReason is the reason the server container last terminated, e.g. OOMKilled or Error.
+	LastTerminationReason string `json:"lastTerminationReason,omitempty"`
+	// LastTerminationExitCode is the exit code the server container last terminated with.
+	LastTerminationExitCode *int32 `json:"lastTerminationExitCode,omitempty"`
+	// LastTerminationTimestamp is the time the server container last terminated.
+	LastTerminationTimestamp *metav1.Time `json:"lastTerminationTimestamp,omitempty"`
+}
+
 func init() {
 	SchemeBuilder.Register(&Paper{}, &PaperList{})
 }
@@ -85,3 +961,30 @@ func init() {
 func (dv *Version) String() string {
 	return fmt.Sprintf("%s-%d", strings.Replace(dv.Version, ".", "-", -1), dv.Build)
 }
+
+// GetUpdatePolicy returns the effective update policy, applying the default if none is given.
+func (ps *PaperSpec) GetUpdatePolicy() UpdatePolicy {
+	if ps.UpdatePolicy != "" {
+		return ps.UpdatePolicy
+	}
+	if ps.Build != nil {
+		return UpdatePolicyPinned
+	}
+	return UpdatePolicyLatestBuild
+}
+
+// GetWorkload returns the effective workload, applying the default if none is given.
+func (ps *PaperSpec) GetWorkload() Workload {
+	if ps.Workload != "" {
+		return ps.Workload
+	}
+	return WorkloadPod
+}
+
+// GetChannel returns the effective channel, applying the default if none is given.
+func (ps *PaperSpec) GetChannel() Channel {
+	if ps.Channel != "" {
+		return ps.Channel
+	}
+	return ChannelExperimental
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 Bernhard Aichinger.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PaperCommandSpec defines the console commands to run once against a Paper resource
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable, create a new PaperCommand instead"
+type PaperCommandSpec struct {
+	// PaperRef references the Paper resource in the same namespace to run the commands against.
+	// +kubebuilder:validation:Required
+	PaperRef corev1.LocalObjectReference `json:"paperRef"`
+
+	// Commands are run in order via RCON, without leading slash, e.g. "whitelist add alice". Execution stops at the
+	// first failing command.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:MinLength=1
+	// +kubebuilder:validation:items:MaxLength=1446
+	Commands []string `json:"commands"`
+
+	// DelaySeconds postpones the execution, counted from the creation of the resource.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DelaySeconds *int32 `json:"delaySeconds,omitempty"`
+}
+
+// PaperCommandPhase is the phase of the execution
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type PaperCommandPhase string
+
+const (
+	// PaperCommandPhasePending waits for the delay to pass and the server to be available.
+	PaperCommandPhasePending PaperCommandPhase = "Pending"
+	// PaperCommandPhaseRunning runs the commands.
+	PaperCommandPhaseRunning PaperCommandPhase = "Running"
+	// PaperCommandPhaseSucceeded ran all commands.
+	PaperCommandPhaseSucceeded PaperCommandPhase = "Succeeded"
+	// PaperCommandPhaseFailed stopped at a failing command, or was interrupted. Commands are never run twice.
+	PaperCommandPhaseFailed PaperCommandPhase = "Failed"
+)
+
+// CommandResult records the execution of a single command
+type CommandResult struct {
+	Command string `json:"command"`
+	// Output is the response of the server.
+	Output string `json:"output,omitempty"`
+	// Succeeded is false if the command could not be run, see Error.
+	Succeeded bool   `json:"succeeded"`
+	Error     string `json:"error,omitempty"`
+}
+
+// PaperCommandStatus defines the observed state of PaperCommand
+type PaperCommandStatus struct {
+	Phase PaperCommandPhase `json:"phase,omitempty"`
+	// Message explains the phase, e.g. what a pending execution waits for.
+	Message string `json:"message,omitempty"`
+	// Results holds the commands run so far, in order.
+	Results []CommandResult `json:"results,omitempty"`
+
+	StartedTimestamp   *metav1.Time `json:"startedTimestamp,omitempty"`
+	CompletedTimestamp *metav1.Time `json:"completedTimestamp,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Paper",type=string,JSONPath=`.spec.paperRef.name`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PaperCommand is the Schema for the papercommands API
+type PaperCommand struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec   PaperCommandSpec   `json:"spec"`
+	Status PaperCommandStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PaperCommandList contains a list of PaperCommand
+type PaperCommandList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PaperCommand `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PaperCommand{}, &PaperCommandList{})
+}
+
+// Done reports whether the execution is finished, successfully or not.
+func (s *PaperCommandStatus) Done() bool {
+	return s.Phase == PaperCommandPhaseSucceeded || s.Phase == PaperCommandPhaseFailed
+}
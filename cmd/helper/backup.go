@@ -0,0 +1,365 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/baichinger/papermc-operator/pkg/papermc/rcon"
+	"github.com/baichinger/papermc-operator/pkg/papermc/s3"
+)
+
+const (
+	backupTimestampFormat = "20060102T150405Z"
+	backupSuffix          = ".tar.gz"
+)
+
+// backupExcluded are the directories of the data directory left out of backups, the server recreates them.
+var backupExcluded = map[string]bool{
+	"cache":     true,
+	"libraries": true,
+	"logs":      true,
+	"versions":  true,
+}
+
+// backupResult is written to the termination message, the operator reports it in the status of the Paper resource.
+type backupResult struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// backupTarget stores the archives of a server.
+type backupTarget interface {
+	// store moves the archive into the target under the given name.
+	store(ctx context.Context, archive *os.File, name string) error
+	// list returns the names of all archives in the target.
+	list(ctx context.Context) ([]string, error)
+	// remove deletes the archive of the given name.
+	remove(ctx context.Context, name string) error
+}
+
+// backup archives the data directory of a running server into a directory or a bucket, and deletes the oldest
+// archives beyond the retention. Saving is turned off via RCON while archiving, so the worlds on disk stay consistent.
+func backup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	data := fs.String("data", "/app/data", "The data directory of the server.")
+	name := fs.String("name", "", "The name of the server, archives are named after it.")
+	retention := fs.Int("retention", 7, "The number of archives kept.")
+	rconAddress := fs.String("rcon-address", "127.0.0.1:25575", "The address RCON listens on.")
+	dir := fs.String("dir", "", "The directory archives are stored in.")
+	s3Endpoint := fs.String("s3-endpoint", "", "The endpoint of the storage archives are uploaded to.")
+	s3Bucket := fs.String("s3-bucket", "", "The bucket archives are uploaded to.")
+	s3Prefix := fs.String("s3-prefix", "", "The prefix of the keys of archives.")
+	s3Region := fs.String("s3-region", "", "The region requests are signed for.")
+	work := fs.String("work", os.TempDir(), "The directory archives are written to before uploading.")
+	terminationLog := fs.String("termination-log", "/dev/termination-log", "The file the result is written to, if any.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return errors.New("--name is required")
+	}
+	if *retention < 1 {
+		return errors.New("--retention must be at least 1")
+	}
+	password := os.Getenv("RCON_PASSWORD")
+	if password == "" {
+		return errors.New("RCON_PASSWORD is not set")
+	}
+
+	var target backupTarget
+	staging := *dir
+	switch {
+	case *dir != "" && *s3Endpoint == "":
+		if err := os.MkdirAll(*dir, 0755); err != nil {
+			return err
+		}
+		target = dirTarget(*dir)
+	case *dir == "" && *s3Endpoint != "":
+		client, err := s3.New(*s3Endpoint, *s3Bucket, *s3Region, os.Getenv("S3_ACCESS_KEY_ID"), os.Getenv("S3_SECRET_ACCESS_KEY"))
+		if err != nil {
+			return err
+		}
+		target = &s3Target{client: client, prefix: *s3Prefix}
+		staging = *work
+	default:
+		return errors.New("exactly one of --dir and --s3-endpoint must be given")
+	}
+
+	ctx := context.Background()
+	started := time.Now().UTC()
+	archiveName := fmt.Sprintf("%s-%s%s", *name, started.Format(backupTimestampFormat), backupSuffix)
+
+	archive, err := os.CreateTemp(staging, ".backup-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	// the worlds on disk are consistent once saved, until saving is turned on again
+	if err := rconCommands(ctx, *rconAddress, password, "save-off", "save-all flush"); err != nil {
+		_ = resumeSaving(*rconAddress, password)
+		return fmt.Errorf("failed to pause saving: %w", err)
+	}
+	fmt.Printf("backup: saving paused, archiving %s\n", *data)
+	archiveErr := writeArchive(archive, *data)
+	if err := resumeSaving(*rconAddress, password); err != nil {
+		return fmt.Errorf("failed to resume saving, turn it on by save-on: %w", err)
+	}
+	if archiveErr != nil {
+		return fmt.Errorf("failed to archive %s: %w", *data, archiveErr)
+	}
+	fmt.Println("backup: saving resumed")
+
+	info, err := archive.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := target.store(ctx, archive, archiveName); err != nil {
+		return fmt.Errorf("failed to store %s: %w", archiveName, err)
+	}
+	fmt.Printf("backup: stored %s (%d bytes)\n", archiveName, info.Size())
+
+	if err := pruneBackups(ctx, target, *name, *retention); err != nil {
+		return err
+	}
+
+	if *terminationLog != "" {
+		result, err := json.Marshal(backupResult{Name: archiveName, Size: info.Size(), Timestamp: time.Now().UTC()})
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(*terminationLog, result, 0644)
+	}
+	return nil
+}
+
+// rconCommands runs the commands via RCON in order.
+func rconCommands(ctx context.Context, address string, password string, commands ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	client, err := rcon.Dial(ctx, address, password)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	for _, command := range commands {
+		output, err := client.Command(ctx, command)
+		if err != nil {
+			return fmt.Errorf("%s: %w", command, err)
+		}
+		fmt.Printf("backup: %s: %s\n", command, output)
+	}
+	return nil
+}
+
+// resumeSaving turns saving on again, retrying for a while, the server would not save the worlds otherwise.
+func resumeSaving(address string, password string) error {
+	var err error
+	for attempt := 0; attempt < 5; attempt++ {
+		if attempt > 0 {
+			time.Sleep(5 * time.Second)
+		}
+		if err = rconCommands(context.Background(), address, password, "save-on"); err == nil {
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "backup: %v\n", err)
+	}
+	return err
+}
+
+// writeArchive writes the data directory as gzipped tar, leaving out the directories the server recreates.
+func writeArchive(w io.Writer, data string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.WalkDir(data, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				// removed in the meantime
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(data, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if d.IsDir() && backupExcluded[rel] {
+			return filepath.SkipDir
+		}
+		return archiveEntry(tw, path, filepath.ToSlash(rel))
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// archiveEntry writes a directory, regular file or symlink to the archive, other types are skipped.
+func archiveEntry(tw *tar.Writer, path string, name string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	link := ""
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		if link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	case info.IsDir():
+		name += "/"
+	case !info.Mode().IsRegular():
+		return nil
+	}
+
+	var f *os.File
+	if info.Mode().IsRegular() {
+		if f, err = os.Open(path); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		defer f.Close()
+	}
+
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	hdr.Uname, hdr.Gname = "", ""
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	if f != nil {
+		if _, err := io.CopyN(tw, f, hdr.Size); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// pruneBackups deletes the oldest archives of the server beyond the retention.
+func pruneBackups(ctx context.Context, target backupTarget, name string, retention int) error {
+	names, err := target.list(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var archives []string
+	for _, n := range names {
+		if isBackupOf(n, name) {
+			archives = append(archives, n)
+		}
+	}
+	// the timestamps sort chronologically
+	sort.Strings(archives)
+
+	for len(archives) > retention {
+		if err := target.remove(ctx, archives[0]); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", archives[0], err)
+		}
+		fmt.Printf("backup: deleted %s\n", archives[0])
+		archives = archives[1:]
+	}
+	return nil
+}
+
+// isBackupOf reports whether the archive of the given name is a backup of the server, and not of another server whose
+// name starts alike.
+func isBackupOf(archive string, name string) bool {
+	timestamp, found := strings.CutPrefix(archive, name+"-")
+	if !found {
+		return false
+	}
+	timestamp, found = strings.CutSuffix(timestamp, backupSuffix)
+	if !found {
+		return false
+	}
+	_, err := time.Parse(backupTimestampFormat, timestamp)
+	return err == nil
+}
+
+// dirTarget stores archives in a directory, e.g. on another volume.
+type dirTarget string
+
+func (t dirTarget) store(_ context.Context, archive *os.File, name string) error {
+	// written within the directory, renaming is atomic
+	return os.Rename(archive.Name(), filepath.Join(string(t), name))
+}
+
+func (t dirTarget) list(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(string(t))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+func (t dirTarget) remove(_ context.Context, name string) error {
+	return os.Remove(filepath.Join(string(t), name))
+}
+
+// s3Target uploads archives to a bucket.
+type s3Target struct {
+	client *s3.Client
+	prefix string
+}
+
+func (t *s3Target) store(ctx context.Context, archive *os.File, name string) error {
+	return t.client.Put(ctx, t.prefix+name, archive)
+}
+
+func (t *s3Target) list(ctx context.Context) ([]string, error) {
+	objects, err := t.client.List(ctx, t.prefix)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, o := range objects {
+		if name := strings.TrimPrefix(o.Key, t.prefix); !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (t *s3Target) remove(ctx context.Context, name string) error {
+	return t.client.Delete(ctx, t.prefix+name)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBackupOf(t *testing.T) {
+	tests := []struct {
+		archive string
+		want    bool
+	}{
+		{archive: "paper-20231001T040000Z.tar.gz", want: true},
+		{archive: "paper-lobby-20231001T040000Z.tar.gz", want: false},
+		{archive: "paper-20231001T040000Z.tar", want: false},
+		{archive: "paper-latest.tar.gz", want: false},
+		{archive: "other-20231001T040000Z.tar.gz", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.archive, func(t *testing.T) {
+			assert.Equal(t, tt.want, isBackupOf(tt.archive, "paper"))
+		})
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"paper-20231003T040000Z.tar.gz",
+		"paper-20231001T040000Z.tar.gz",
+		"paper-20231002T040000Z.tar.gz",
+		"paper-lobby-20230901T040000Z.tar.gz",
+		"notes.txt",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+
+	require.NoError(t, pruneBackups(context.Background(), dirTarget(dir), "paper", 2))
+
+	names, err := dirTarget(dir).list(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"paper-20231002T040000Z.tar.gz",
+		"paper-20231003T040000Z.tar.gz",
+		"paper-lobby-20230901T040000Z.tar.gz",
+		"notes.txt",
+	}, names)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pluginsManifest lists the plugins installed by the operator within the plugins directory of the server, other
+// plugins are left alone.
+const pluginsManifest = ".papermc-operator-plugins"
+
+// accessFiles are the lists of ops, whitelisted and banned players, replaced as a whole if managed by the operator.
+var accessFiles = []string{"ops.json", "whitelist.json", "banned-players.json", "banned-ips.json"}
+
+// configure writes server.properties into the data directory before the server starts. Properties managed by the
+// operator are merged onto the ones written by the server, and RCON is enabled if a password is given by the
+// environment variable RCON_PASSWORD. Settings of Paper, Bukkit and Spigot are merged onto their configuration files
+// the same way. Access files given by the operator replace the ones of the server, and plugins given by the operator
+// are installed, replacing the ones installed before. Config files given by the operator are copied last, so they may
+// override any of the files above.
+func configure(args []string) error {
+	fs := flag.NewFlagSet("configure", flag.ContinueOnError)
+	config := fs.String("config", "/config", "The directory holding the configuration managed by the operator.")
+	data := fs.String("data", "/app/data", "The data directory of the server.")
+	rconPort := fs.Int("rcon-port", 25575, "The port RCON listens on.")
+	plugins := fs.String("plugins", "", "The directory holding the plugins managed by the operator, if any.")
+	files := fs.String("files", "", "The directory holding the config files managed by the operator, if any.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	target := filepath.Join(*data, "server.properties")
+
+	properties, err := readProperties(target)
+	if err != nil {
+		return err
+	}
+
+	managed, err := readProperties(filepath.Join(*config, "server.properties"))
+	if err != nil {
+		return err
+	}
+	for k, v := range managed {
+		properties[k] = v
+	}
+
+	if password := os.Getenv("RCON_PASSWORD"); password != "" {
+		properties["enable-rcon"] = "true"
+		properties["rcon.port"] = fmt.Sprint(*rconPort)
+		properties["rcon.password"] = password
+	}
+
+	if err := writeProperties(target, properties); err != nil {
+		return err
+	}
+
+	if err := mergeFiles(*config, *data); err != nil {
+		return err
+	}
+
+	for _, name := range accessFiles {
+		content, err := os.ReadFile(filepath.Join(*config, name))
+		if os.IsNotExist(err) {
+			// not managed, keep the one of the server
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(*data, name), content, 0644); err != nil {
+			return err
+		}
+	}
+
+	if err := installPlugins(*plugins, filepath.Join(*data, "plugins")); err != nil {
+		return err
+	}
+
+	if *files != "" {
+		return copyFiles(*files, *data)
+	}
+	return nil
+}
+
+// installPlugins copies the plugins of the source directory into the plugins directory of the server. Plugins
+// installed before but no longer given are removed, their data directories are kept. Without source directory, all
+// plugins installed before are removed.
+func installPlugins(source string, target string) error {
+	manifest := filepath.Join(target, pluginsManifest)
+
+	installed, err := os.ReadFile(manifest)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, name := range strings.Fields(string(installed)) {
+		if err := os.Remove(filepath.Join(target, filepath.Base(name))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	var names []string
+	if source != "" {
+		jars, err := filepath.Glob(filepath.Join(source, "*.jar"))
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return err
+		}
+		for _, jar := range jars {
+			content, err := os.ReadFile(jar)
+			if err != nil {
+				return err
+			}
+			name := filepath.Base(jar)
+			if err := writeFile(filepath.Join(target, name), content, 0644); err != nil {
+				return err
+			}
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		if err := os.Remove(manifest); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return writeFile(manifest, []byte(strings.Join(names, "\n")+"\n"), 0644)
+}
+
+// readProperties reads the raw keys and values of a properties file, comments are dropped. A missing file has no
+// properties.
+func readProperties(path string) (map[string]string, error) {
+	properties := map[string]string{}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return properties, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		k, v, _ := strings.Cut(line, "=")
+		properties[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	return properties, scanner.Err()
+}
+
+// writeProperties writes the properties sorted by key, replacing the file atomically.
+func writeProperties(path string, properties map[string]string) error {
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(properties[k])
+		sb.WriteString("\n")
+	}
+
+	return writeFile(path, []byte(sb.String()), 0600)
+}
+
+// writeFile replaces the file atomically.
+func writeFile(path string, content []byte, mode os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
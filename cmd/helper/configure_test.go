@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProperties(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.properties")
+	require.NoError(t, os.WriteFile(path, []byte(`#Minecraft server properties
+! legacy comment
+motd=A Minecraft Server
+ max-players = 20
+level-seed=
+enable-rcon
+`), 0644))
+
+	properties, err := readProperties(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"motd":        "A Minecraft Server",
+		"max-players": "20",
+		"level-seed":  "",
+		"enable-rcon": "",
+	}, properties)
+}
+
+func TestReadPropertiesMissing(t *testing.T) {
+	properties, err := readProperties(filepath.Join(t.TempDir(), "server.properties"))
+	require.NoError(t, err)
+	assert.Empty(t, properties)
+}
+
+func TestInstallPlugins(t *testing.T) {
+	source := t.TempDir()
+	target := filepath.Join(t.TempDir(), "plugins")
+	writeJars := func(names ...string) {
+		entries, err := os.ReadDir(source)
+		require.NoError(t, err)
+		for _, e := range entries {
+			require.NoError(t, os.Remove(filepath.Join(source, e.Name())))
+		}
+		for _, name := range names {
+			require.NoError(t, os.WriteFile(filepath.Join(source, name), []byte(name), 0644))
+		}
+	}
+	installed := func() []string {
+		entries, err := os.ReadDir(target)
+		require.NoError(t, err)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		return names
+	}
+
+	// plugins installed by hand and their data directories are kept
+	require.NoError(t, os.MkdirAll(filepath.Join(target, "LuckPerms"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(target, "Manual.jar"), nil, 0644))
+
+	writeJars("LuckPerms.jar", "Chunky.jar")
+	require.NoError(t, installPlugins(source, target))
+	assert.ElementsMatch(t, []string{pluginsManifest, "Chunky.jar", "LuckPerms", "LuckPerms.jar", "Manual.jar"}, installed())
+
+	writeJars("LuckPerms.jar")
+	require.NoError(t, installPlugins(source, target))
+	assert.ElementsMatch(t, []string{pluginsManifest, "LuckPerms", "LuckPerms.jar", "Manual.jar"}, installed())
+
+	require.NoError(t, installPlugins("", target))
+	assert.ElementsMatch(t, []string{"LuckPerms", "Manual.jar"}, installed())
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/baichinger/papermc-operator/pkg/papermc/rcon"
+)
+
+// filesManifest lists the config files within the directory given by the operator.
+const filesManifest = "manifest.json"
+
+// configFile is an entry of the manifest, the content is held by the file named by key.
+type configFile struct {
+	Key           string `json:"key"`
+	Path          string `json:"path"`
+	ReloadCommand string `json:"reloadCommand,omitempty"`
+}
+
+// readFilesManifest reads the manifest of the config files within the given directory.
+func readFilesManifest(dir string) ([]configFile, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, filesManifest))
+	if err != nil {
+		return nil, err
+	}
+	var files []configFile
+	if err := json.Unmarshal(raw, &files); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return files, nil
+}
+
+// copyFiles copies all config files of the source directory into the data directory.
+func copyFiles(source string, data string) error {
+	files, err := readFilesManifest(source)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		content, err := os.ReadFile(filepath.Join(source, f.Key))
+		if err != nil {
+			return err
+		}
+		if err := copyFile(data, f, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile writes the content of a config file to its path within the data directory, creating missing directories.
+func copyFile(data string, f configFile, content []byte) error {
+	if !filepath.IsLocal(f.Path) {
+		return fmt.Errorf("path %s is not within the data directory", f.Path)
+	}
+	target := filepath.Join(data, f.Path)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return writeFile(target, content, 0600)
+}
+
+// syncFiles keeps config files with a reload command in sync with the directory given by the operator while the
+// server is running. Changed files are copied into the data directory and their reload commands are run via RCON,
+// failed commands are retried with the next poll. Config files without reload command are left to the operator, which
+// restarts the server instead.
+func syncFiles(args []string) error {
+	fs := flag.NewFlagSet("sync-files", flag.ContinueOnError)
+	source := fs.String("files", "/config-files", "The directory holding the config files managed by the operator.")
+	data := fs.String("data", "/app/data", "The data directory of the server.")
+	rconAddress := fs.String("rcon-address", "127.0.0.1:25575", "The address RCON listens on.")
+	interval := fs.Duration("interval", 10*time.Second, "The interval to poll for changes.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	password := os.Getenv("RCON_PASSWORD")
+	if password == "" {
+		return errors.New("RCON_PASSWORD is not set")
+	}
+
+	// the files were copied before the server started
+	synced := map[string]string{}
+	files, err := readFilesManifest(*source)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if content, err := os.ReadFile(filepath.Join(*source, f.Key)); err == nil {
+			synced[f.Path] = hashOfContent(content)
+		}
+	}
+
+	pending := map[string]bool{}
+	for range time.Tick(*interval) {
+		files, err := readFilesManifest(*source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "sync-files: %v\n", err)
+			continue
+		}
+
+		for _, f := range files {
+			if f.ReloadCommand == "" {
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(*source, f.Key))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "sync-files: %v\n", err)
+				continue
+			}
+			hash := hashOfContent(content)
+			if synced[f.Path] == hash {
+				continue
+			}
+			if err := copyFile(*data, f, content); err != nil {
+				fmt.Fprintf(os.Stderr, "sync-files: %v\n", err)
+				continue
+			}
+			fmt.Printf("sync-files: %s changed\n", f.Path)
+			synced[f.Path] = hash
+			pending[f.ReloadCommand] = true
+		}
+
+		if len(pending) > 0 {
+			runReloadCommands(*rconAddress, password, pending, *interval)
+		}
+	}
+	return nil
+}
+
+// runReloadCommands runs the pending commands via RCON, commands run successfully are removed.
+func runReloadCommands(address string, password string, pending map[string]bool, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client, err := rcon.Dial(ctx, address, password)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sync-files: %v\n", err)
+		return
+	}
+	defer client.Close()
+
+	for command := range pending {
+		output, err := client.Command(ctx, command)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "sync-files: %s: %v\n", command, err)
+			return
+		}
+		fmt.Printf("sync-files: %s: %s\n", command, output)
+		delete(pending, command)
+	}
+}
+
+func hashOfContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// install copies the helper into the given directory, usually a volume shared with the server container.
+func install(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected target directory")
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(self)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	target := filepath.Join(args[0], filepath.Base(self))
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// javaVersion prints the major version of Java found in the PATH, e.g. to check whether an image runs a version of
+// Minecraft. The version is written to the termination message of the container as well.
+func javaVersion(args []string) error {
+	fs := flag.NewFlagSet("java-version", flag.ContinueOnError)
+	terminationLog := fs.String("termination-log", "/dev/termination-log", "The file the version is written to, if any.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	output, err := exec.Command("java", "-XshowSettings:properties", "-version").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to run java: %w: %s", err, bytes.TrimSpace(output))
+	}
+
+	version, err := parseJavaVersion(output)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(version)
+
+	if *terminationLog != "" {
+		return os.WriteFile(*terminationLog, []byte(strconv.Itoa(version)), 0644)
+	}
+	return nil
+}
+
+// parseJavaVersion reads the major version from the specification version among the properties printed by java, which
+// is 1.8 for Java 8 and 17 for Java 17.
+func parseJavaVersion(output []byte) (int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		k, v, found := strings.Cut(scanner.Text(), "=")
+		if !found || strings.TrimSpace(k) != "java.specification.version" {
+			continue
+		}
+		v = strings.TrimPrefix(strings.TrimSpace(v), "1.")
+		major, _, _ := strings.Cut(v, ".")
+		return strconv.Atoi(major)
+	}
+	return 0, fmt.Errorf("java did not report its specification version")
+}
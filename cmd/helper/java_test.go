@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJavaVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    int
+	}{
+		{name: "java 8", version: "1.8", want: 8},
+		{name: "java 17", version: "17", want: 17},
+		{name: "java 21", version: "21", want: 21},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := "Property settings:\n" +
+				"    java.home = /opt/java/openjdk\n" +
+				"    java.specification.vendor = Oracle Corporation\n" +
+				"    java.specification.version = " + tt.version + "\n" +
+				"    java.vendor = Eclipse Adoptium\n" +
+				"\n" +
+				"openjdk version \"" + tt.version + "\"\n"
+
+			version, err := parseJavaVersion([]byte(output))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, version)
+		})
+	}
+}
+
+func TestParseJavaVersionMissing(t *testing.T) {
+	_, err := parseJavaVersion([]byte("openjdk version \"17.0.9\" 2023-10-17\n"))
+	assert.Error(t, err)
+}
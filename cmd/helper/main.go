@@ -0,0 +1,64 @@
+/*
+Copyright 2022 Bernhard Aichinger.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The helper is shipped in the operator image and copied into the Pods of a Paper resource, where it runs tasks the
+// server image lacks tools for.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command runs a subcommand with its arguments.
+type command func(args []string) error
+
+var commands = map[string]command{
+	"backup":       backup,
+	"configure":    configure,
+	"install":      install,
+	"java-version": javaVersion,
+	"probe":        probe,
+	"sync-files":   syncFiles,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [flags], commands: %v\n", os.Args[0], names)
+}
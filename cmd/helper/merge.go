@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergedFiles maps the settings given by the operator to the configuration files of the server they are merged onto.
+var mergedFiles = map[string]string{
+	"paper-global.yml":         "config/paper-global.yml",
+	"paper-world-defaults.yml": "config/paper-world-defaults.yml",
+	"bukkit.yml":               "bukkit.yml",
+	"spigot.yml":               "spigot.yml",
+}
+
+// mergeFiles merges the settings of the config directory onto the configuration files within the data directory.
+// Files are created if missing, the server completes them with its defaults on start.
+func mergeFiles(config string, data string) error {
+	for name, path := range mergedFiles {
+		settings, err := os.ReadFile(filepath.Join(config, name))
+		if os.IsNotExist(err) {
+			// not managed, keep the one of the server
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		target := filepath.Join(data, path)
+		existing, err := os.ReadFile(target)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		merged, err := mergeYaml(existing, settings)
+		if err != nil {
+			return fmt.Errorf("failed to merge %s: %w", path, err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := writeFile(target, merged, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeYaml merges the settings onto the existing document. Comments, order and formatting of the existing document
+// are kept, as far as not replaced.
+func mergeYaml(existing []byte, settings []byte) ([]byte, error) {
+	var overlay yaml.Node
+	if err := yaml.Unmarshal(settings, &overlay); err != nil {
+		return nil, err
+	}
+	if len(overlay.Content) == 0 || overlay.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("settings must be a mapping")
+	}
+	// settings are given as JSON, render them in block style
+	resetStyle(overlay.Content[0])
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(existing, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	} else if document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("existing document is not a mapping")
+	}
+	mergeNode(document.Content[0], overlay.Content[0])
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeNode merges the overlay mapping onto the target mapping recursively, other values are replaced.
+func mergeNode(target *yaml.Node, overlay *yaml.Node) {
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+
+		found := false
+		for j := 0; j+1 < len(target.Content); j += 2 {
+			if target.Content[j].Value != key.Value {
+				continue
+			}
+			found = true
+			if existing := target.Content[j+1]; existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+				mergeNode(existing, value)
+			} else {
+				// keep comments of the replaced value
+				value.HeadComment, value.LineComment, value.FootComment = existing.HeadComment, existing.LineComment, existing.FootComment
+				target.Content[j+1] = value
+			}
+			break
+		}
+		if !found {
+			target.Content = append(target.Content, key, value)
+		}
+	}
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
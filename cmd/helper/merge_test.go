@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeYaml(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		settings string
+		want     string
+		err      bool
+	}{
+		{
+			name:     "onto empty file",
+			settings: `{"settings":{"allow-end":false}}`,
+			want: `settings:
+  allow-end: false
+`,
+		},
+		{
+			name: "add and replace scalars",
+			existing: `settings:
+  allow-end: true
+  connection-throttle: 4000
+`,
+			settings: `{"settings":{"allow-end":false,"warn-on-overload":true}}`,
+			want: `settings:
+  allow-end: false
+  connection-throttle: 4000
+  warn-on-overload: true
+`,
+		},
+		{
+			name: "merge nested mapping",
+			existing: `proxies:
+  velocity:
+    enabled: false
+    online-mode: true
+`,
+			settings: `{"proxies":{"velocity":{"enabled":true}}}`,
+			want: `proxies:
+  velocity:
+    enabled: true
+    online-mode: true
+`,
+		},
+		{
+			name: "replace mapping by scalar",
+			existing: `aliases:
+  icanhasbukkit:
+  - version $1-
+`,
+			settings: `{"aliases":"now-in-commands.yml"}`,
+			want: `aliases: now-in-commands.yml
+`,
+		},
+		{
+			name: "replace list",
+			existing: `worlds:
+- world
+- world_nether
+`,
+			settings: `{"worlds":["lobby"]}`,
+			want: `worlds:
+  - lobby
+`,
+		},
+		{
+			name: "keep comments",
+			existing: `# This is the main configuration file for Bukkit.
+settings:
+  # allows the end
+  allow-end: true # line comment
+  shutdown-message: Server closed
+`,
+			settings: `{"settings":{"allow-end":false}}`,
+			want: `# This is the main configuration file for Bukkit.
+settings:
+  # allows the end
+  allow-end: false # line comment
+  shutdown-message: Server closed
+`,
+		},
+		{
+			name:     "settings not a mapping",
+			settings: `["allow-end"]`,
+			err:      true,
+		},
+		{
+			name:     "existing document not a mapping",
+			existing: "- world\n",
+			settings: `{"worlds":["lobby"]}`,
+			err:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := mergeYaml([]byte(tt.existing), []byte(tt.settings))
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(merged))
+		})
+	}
+}
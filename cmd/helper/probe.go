@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/baichinger/papermc-operator/pkg/papermc/slp"
+)
+
+// probe succeeds if the server answers a server list ping, which it does only once the worlds are loaded.
+func probe(args []string) error {
+	fs := flag.NewFlagSet("probe", flag.ContinueOnError)
+	address := fs.String("address", "127.0.0.1:25565", "The address of the server.")
+	timeout := fs.Duration("timeout", 1*time.Second, "The time to wait for the server to answer.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	status, err := slp.Ping(ctx, *address)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s, %d/%d players online\n", status.Version.Name, status.Players.Online, status.Players.Max)
+
+	return nil
+}
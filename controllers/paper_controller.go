@@ -20,12 +20,18 @@ import (
 	"context"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
 	"github.com/baichinger/papermc-operator/pkg/papermc/reconciler"
@@ -36,29 +42,104 @@ var (
 	requeue   = ctrl.Result{RequeueAfter: 1 * time.Hour}
 )
 
+const (
+	// configFilesConfigMapsIndex indexes Paper objects by the ConfigMaps their config files are read from
+	configFilesConfigMapsIndex = "spec.configFiles.configMaps"
+	// configFilesSecretsIndex indexes Paper objects by the Secrets their config files are read from or insert values of
+	configFilesSecretsIndex = "spec.configFiles.secrets"
+)
+
 // PaperController reconciles a Paper object
 type PaperController struct {
 	client.Client
-	Scheme *runtime.Scheme
+	// APIReader reads objects bypassing the cache
+	APIReader client.Reader
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+	Options   reconciler.Options
 }
 
-// SetupWithManager sets up the controller with the Manager.
+// SetupWithManager sets up the controller with the Manager. ConfigMaps and Secrets of users are watched as long as
+// config files reference them, their changes are rendered right away.
 func (c *PaperController) SetupWithManager(mgr ctrl.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+	if err := indexer.IndexField(context.Background(), &papermciov1.Paper{}, configFilesConfigMapsIndex, configFilesConfigMaps); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(context.Background(), &papermciov1.Paper{}, configFilesSecretsIndex, configFilesSecrets); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&papermciov1.Paper{}).
+		For(&papermciov1.Paper{}, builder.WithPredicates(ignoreServerStatus())).
 		Owns(&corev1.Pod{}).
 		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Secret{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&appsv1.StatefulSet{}).
+		Owns(&batchv1.CronJob{}).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(c.papersReferencing(configFilesConfigMapsIndex))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(c.papersReferencing(configFilesSecretsIndex))).
 		Complete(c)
 }
 
+// papersReferencing maps an object to the Paper objects in its namespace referencing it by name, as of the given
+// index.
+func (c *PaperController) papersReferencing(index string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		papers := papermciov1.PaperList{}
+		if err := c.List(ctx, &papers, client.InNamespace(obj.GetNamespace()), client.MatchingFields{index: obj.GetName()}); err != nil {
+			log.FromContext(ctx).Error(err, "failed to list Paper resources referencing object", "index", index, "name", obj.GetName())
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(papers.Items))
+		for i := range papers.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&papers.Items[i])})
+		}
+		return requests
+	}
+}
+
+// configFilesConfigMaps are the names of the ConfigMaps the config files of a Paper object are read from.
+func configFilesConfigMaps(obj client.Object) []string {
+	var names []string
+	for _, f := range obj.(*papermciov1.Paper).Spec.ConfigFiles {
+		if f.ConfigMap != nil {
+			names = append(names, f.ConfigMap.Name)
+		}
+	}
+	return names
+}
+
+// configFilesSecrets are the names of the Secrets the config files of a Paper object are read from, or their templates
+// insert values of.
+func configFilesSecrets(obj client.Object) []string {
+	var names []string
+	for _, f := range obj.(*papermciov1.Paper).Spec.ConfigFiles {
+		if f.Secret != nil {
+			names = append(names, f.Secret.Name)
+		}
+		for _, ref := range f.SecretRefs {
+			names = append(names, ref.Name)
+		}
+	}
+	return names
+}
+
 // +kubebuilder:rbac:groups=papermc.io,resources=papers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=papermc.io,resources=papers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups="",resources=service,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.13.0/pkg/reconcile
@@ -76,27 +157,51 @@ func (c *PaperController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return noRequeue, err
 	}
 
-	r := reconciler.NewPaperReconciler(c.Client, c.Scheme, ctx, p)
+	r := reconciler.NewPaperReconciler(c.Client, c.APIReader, c.Scheme, c.Recorder, ctx, p, c.Options)
 
 	// initialize status (.status.conditions)
 	if res := r.InitializeConditions(); res.Failed() {
-		return noRequeue, res.GetError()
+		return c.failed(p, res)
 	} else if res.Updated() {
 		logger.Info("initial status reconciled")
 		return noRequeue, nil
 	}
 
+	// refuse to run without acceptance of the EULA
+	if res := r.ReconcileEula(); res.Failed() {
+		return c.failed(p, res)
+	} else if res.Updated() {
+		logger.Info("eula reconciled")
+		return noRequeue, nil
+	}
+
 	// figure desired version/artifact details
 	if res := r.ReconcileDesiredVersion(); res.Failed() {
-		return noRequeue, res.GetError()
+		return c.failed(p, res)
 	} else if res.Updated() {
 		logger.Info("desired version reconciled")
 		return noRequeue, nil
 	}
 
+	// select images for desired version
+	if res := r.ReconcileRuntime(); res.Failed() {
+		return c.failed(p, res)
+	} else if res.Updated() {
+		logger.Info("runtime reconciled")
+		return noRequeue, nil
+	}
+
+	// refuse to run desired version on an incompatible java
+	if res := r.ReconcileJavaCompatibility(); res.Failed() {
+		return c.failed(p, res)
+	} else if res.Updated() {
+		logger.Info("java compatibility reconciled")
+		return noRequeue, nil
+	}
+
 	// setup PVC for version/artifact
 	if res := r.ReconcilePersistentVolumeClaimForDesiredVersion(); res.Failed() {
-		return noRequeue, res.GetError()
+		return c.failed(p, res)
 	} else if res.Updated() {
 		logger.Info("pvc for desired version reconciled")
 		return noRequeue, nil
@@ -104,47 +209,111 @@ func (c *PaperController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 
 	// download new version/artifact
 	if res := r.ReconcileProvisionerForDesiredVersion(); res.Failed() {
-		return noRequeue, res.GetError()
+		return c.failed(p, res)
 	} else if res.Updated() {
 		logger.Info("provisioner for desired version reconciled")
 		return noRequeue, nil
 	}
 
+	// resolve plugins
+	if res := r.ReconcilePlugins(); res.Failed() {
+		return c.failed(p, res)
+	} else if res.Updated() {
+		logger.Info("plugins reconciled")
+		return noRequeue, nil
+	}
+
+	// setup PVC for plugins
+	if res := r.ReconcilePersistentVolumeClaimForPlugins(); res.Failed() {
+		return c.failed(p, res)
+	} else if res.Updated() {
+		logger.Info("pvc for plugins reconciled")
+		return noRequeue, nil
+	}
+
+	// download plugins
+	if res := r.ReconcileProvisionerForPlugins(); res.Failed() {
+		return c.failed(p, res)
+	} else if res.Updated() {
+		logger.Info("provisioner for plugins reconciled")
+		return noRequeue, nil
+	}
+
 	// setup PVC for instance
 	if res := r.ReconcilePersistentVolumeClaimForPaperInstance(); res.Failed() {
-		return noRequeue, res.GetError()
+		return c.failed(p, res)
 	} else if res.Updated() {
 		logger.Info("pvc for instance reconciled")
 		return noRequeue, nil
 	}
 
+	// setup rcon password for instance
+	if res := r.ReconcileRconSecret(); res.Failed() {
+		return c.failed(p, res)
+	} else if res.Updated() {
+		logger.Info("rcon secret for instance reconciled")
+		return noRequeue, nil
+	}
+
 	// setup configuration for instance
 	if res := r.ReconcileConfigurationForPaperInstance(); res.Failed() {
-		return noRequeue, res.GetError()
+		return c.failed(p, res)
 	} else if res.Updated() {
 		logger.Info("configuration for instance reconciled")
 		return noRequeue, nil
 	}
 
+	// setup config files copied into the data directory
+	if res := r.ReconcileConfigFiles(); res.Failed() {
+		return c.failed(p, res)
+	} else if res.Updated() {
+		logger.Info("config files for instance reconciled")
+		return noRequeue, nil
+	}
+
 	// run instance with desired version
 	if res := r.ReconcilePaperInstance(); res.Failed() {
-		return noRequeue, res.GetError()
+		return c.failed(p, res)
 	} else if res.Updated() {
 		logger.Info("pod for instance reconciled")
-		return noRequeue, nil
+		return ctrl.Result{RequeueAfter: res.RequeueAfter()}, nil
+	}
+
+	// apply access changes to running instance
+	if res := r.ReconcileAccess(); res.Failed() {
+		return c.failed(p, res)
+	} else if res.Updated() {
+		logger.Info("access for instance reconciled")
+		return ctrl.Result{RequeueAfter: res.RequeueAfter()}, nil
 	}
 
 	// expose instance via loadbalance service
 	if res := r.ReconcilePaperService(); res.Failed() {
-		return noRequeue, res.GetError()
+		return c.failed(p, res)
 	} else if res.Updated() {
 		logger.Info("service for instance reconciled")
 		return noRequeue, nil
 	}
 
+	// expose rcon of instance within the cluster
+	if res := r.ReconcileRconService(); res.Failed() {
+		return c.failed(p, res)
+	} else if res.Updated() {
+		logger.Info("rcon service for instance reconciled")
+		return noRequeue, nil
+	}
+
+	// schedule backups of instance
+	if res := r.ReconcileBackup(); res.Failed() {
+		return c.failed(p, res)
+	} else if res.Updated() {
+		logger.Info("backup for instance reconciled")
+		return noRequeue, nil
+	}
+
 	// update status
 	if res := r.ReconcileStatus(); res.Failed() {
-		return noRequeue, res.GetError()
+		return c.failed(p, res)
 	} else if res.Updated() {
 		logger.Info("status reconciled")
 		return noRequeue, nil
@@ -164,3 +333,11 @@ func (c *PaperController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 
 	return requeue, nil
 }
+
+// failed records the error of a reconciliation step as event on the Paper resource, unless the step did already.
+func (c *PaperController) failed(p *papermciov1.Paper, res reconciler.Result) (ctrl.Result, error) {
+	if !res.Reported() {
+		c.Recorder.Eventf(p, corev1.EventTypeWarning, reconciler.EventReasonReconcileFailed, "Reconciliation failed: %v", res.GetError())
+	}
+	return noRequeue, res.GetError()
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 Bernhard Aichinger.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+func TestPapersReferencing(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, papermciov1.AddToScheme(scheme))
+
+	lobby := &papermciov1.Paper{
+		ObjectMeta: metav1.ObjectMeta{Name: "lobby", Namespace: "default"},
+		Spec: papermciov1.PaperSpec{
+			ConfigFiles: []papermciov1.ConfigFileSpec{{
+				Path: "plugins/LuckPerms/config.yml",
+				ConfigMap: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "luckperms-config"},
+					Key:                  "config.yml",
+				},
+				Template:   true,
+				SecretRefs: []corev1.LocalObjectReference{{Name: "luckperms-db"}},
+			}},
+		},
+	}
+	survival := &papermciov1.Paper{
+		ObjectMeta: metav1.ObjectMeta{Name: "survival", Namespace: "default"},
+		Spec: papermciov1.PaperSpec{
+			ConfigFiles: []papermciov1.ConfigFileSpec{{
+				Path: "plugins/LuckPerms/config.yml",
+				Secret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "luckperms-db"},
+					Key:                  "config.yml",
+				},
+			}},
+		},
+	}
+	other := survival.DeepCopy()
+	other.Namespace = "other"
+
+	c := &PaperController{
+		Client: fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(lobby, survival, other).
+			WithIndex(&papermciov1.Paper{}, configFilesConfigMapsIndex, configFilesConfigMaps).
+			WithIndex(&papermciov1.Paper{}, configFilesSecretsIndex, configFilesSecrets).
+			Build(),
+	}
+
+	tests := []struct {
+		name  string
+		index string
+		obj   metav1.ObjectMeta
+		want  []reconcile.Request
+	}{
+		{
+			name:  "configmap",
+			index: configFilesConfigMapsIndex,
+			obj:   metav1.ObjectMeta{Name: "luckperms-config", Namespace: "default"},
+			want:  []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "lobby"}}},
+		},
+		{
+			name:  "secret",
+			index: configFilesSecretsIndex,
+			obj:   metav1.ObjectMeta{Name: "luckperms-db", Namespace: "default"},
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "lobby"}},
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "survival"}},
+			},
+		},
+		{
+			name:  "unreferenced",
+			index: configFilesSecretsIndex,
+			obj:   metav1.ObjectMeta{Name: "luckperms-config", Namespace: "default"},
+			want:  []reconcile.Request{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := c.papersReferencing(tt.index)(context.Background(), &corev1.Secret{ObjectMeta: tt.obj})
+			assert.ElementsMatch(t, tt.want, requests)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 Bernhard Aichinger.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+	"github.com/baichinger/papermc-operator/pkg/papermc/reconciler"
+)
+
+// PaperCommandController runs the commands of a PaperCommand object once
+type PaperCommandController struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (c *PaperCommandController) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&papermciov1.PaperCommand{}).
+		Complete(c)
+}
+
+// +kubebuilder:rbac:groups=papermc.io,resources=papercommands,verbs=get;list;watch
+// +kubebuilder:rbac:groups=papermc.io,resources=papercommands/status,verbs=get;update;patch
+
+func (c *PaperCommandController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	logger.Info("reconciliation event")
+
+	pc := &papermciov1.PaperCommand{}
+	if err := c.Get(ctx, req.NamespacedName, pc); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("PaperCommand resource not found, ignoring, must be deleted")
+			return noRequeue, nil
+		}
+		return noRequeue, err
+	}
+
+	if pc.Status.Done() {
+		// commands are run once only
+		return noRequeue, nil
+	}
+
+	r := reconciler.NewCommandReconciler(c.Client, c.Recorder, ctx, pc)
+
+	// wait for the delay to pass
+	if res := r.ReconcileDelay(); res.Failed() {
+		return c.failed(pc, res.GetError())
+	} else if res.Updated() {
+		logger.Info("execution delayed")
+		return ctrl.Result{RequeueAfter: res.RequeueAfter()}, nil
+	}
+
+	// run commands
+	if res := r.ReconcileExecution(); res.Failed() {
+		return c.failed(pc, res.GetError())
+	} else if res.Updated() {
+		logger.Info("execution reconciled", "phase", pc.Status.Phase)
+		return ctrl.Result{RequeueAfter: res.RequeueAfter()}, nil
+	}
+
+	return noRequeue, nil
+}
+
+// failed records the error of a reconciliation step as event on the PaperCommand resource.
+func (c *PaperCommandController) failed(pc *papermciov1.PaperCommand, err error) (ctrl.Result, error) {
+	c.Recorder.Eventf(pc, corev1.EventTypeWarning, reconciler.EventReasonReconcileFailed, "Reconciliation failed: %v", err)
+	return noRequeue, err
+}
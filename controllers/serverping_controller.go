@@ -0,0 +1,90 @@
+/*
+Copyright 2022 Bernhard Aichinger.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+	"github.com/baichinger/papermc-operator/pkg/papermc/reconciler"
+)
+
+// ServerPingController refreshes the server status of a Paper object by a server list ping, apart from PaperController
+type ServerPingController struct {
+	client.Client
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (c *ServerPingController) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("paper-server-ping").
+		For(&papermciov1.Paper{}, builder.WithPredicates(predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				// pings are requeued on their own, the instance becoming (not) ready is what matters in between
+				oldPaper, newPaper := e.ObjectOld.(*papermciov1.Paper), e.ObjectNew.(*papermciov1.Paper)
+				return !equality.Semantic.DeepEqual(oldPaper.Status.Instance, newPaper.Status.Instance)
+			},
+		})).
+		Complete(c)
+}
+
+func (c *ServerPingController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	p := &papermciov1.Paper{}
+	if err := c.Get(ctx, req.NamespacedName, p); err != nil {
+		if apierrors.IsNotFound(err) {
+			return noRequeue, nil
+		}
+		return noRequeue, err
+	}
+
+	r := reconciler.NewServerPingReconciler(c.Client, ctx, p)
+
+	res := r.ReconcileServerStatus()
+	if res.Failed() {
+		logger.Info("server ping failed", "error", res.GetError())
+		return noRequeue, res.GetError()
+	}
+
+	return ctrl.Result{RequeueAfter: res.RequeueAfter()}, nil
+}
+
+// ignoreServerStatus filters update events of Paper objects changing nothing but the server status refreshed by
+// ServerPingController.
+func ignoreServerStatus() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPaper, newPaper := e.ObjectOld.(*papermciov1.Paper).DeepCopy(), e.ObjectNew.(*papermciov1.Paper).DeepCopy()
+			for _, p := range []*papermciov1.Paper{oldPaper, newPaper} {
+				p.Status.Server = nil
+				p.ResourceVersion = ""
+				p.ManagedFields = nil
+			}
+			return !equality.Semantic.DeepEqual(oldPaper, newPaper)
+		},
+	}
+}
@@ -18,8 +18,12 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"go.uber.org/zap/zapcore"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -33,6 +37,10 @@ import (
 
 	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
 	"github.com/baichinger/papermc-operator/controllers"
+	papermc "github.com/baichinger/papermc-operator/pkg/papermc/client"
+	"github.com/baichinger/papermc-operator/pkg/papermc/plugin"
+	"github.com/baichinger/papermc-operator/pkg/papermc/profile"
+	"github.com/baichinger/papermc-operator/pkg/papermc/reconciler"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -41,6 +49,15 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
+const (
+	// profileCacheTTL is the time resolved players are cached, names rarely change owner.
+	profileCacheTTL = 24 * time.Hour
+
+	// helperImageEnv names the environment variable the Deployment passes the image of the operator in, it is the
+	// default of --helper-image.
+	helperImageEnv = "HELPER_IMAGE"
+)
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
@@ -52,11 +69,45 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var papermcApiUrl string
+	var papermcApiCaFile string
+	var papermcApiAuthHeader string
+	var helperImage string
+	var mojangApiUrl string
+	var mojangSessionServerUrl string
+	var hangarApiUrl string
+	var modrinthApiUrl string
+	var downloaderImage string
+	var serverImages string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&papermcApiUrl, "papermc-api-url", papermc.DefaultApiUrl,
+		"The base URL of the PaperMC API, or a mirror of it, used for discovering and downloading builds.")
+	flag.StringVar(&papermcApiCaFile, "papermc-api-ca-file", "",
+		"A file of PEM encoded certificates trusted for requests to the PaperMC API instead of the system pool.")
+	flag.StringVar(&papermcApiAuthHeader, "papermc-api-auth-header", "",
+		"A header, given as \"Name: value\", sent with each request of the operator to the PaperMC API. "+
+			"It is not passed on to provisioners, Paper resources downloading from an API requiring it reference "+
+			"their own header by spec.api.authHeader.")
+	flag.StringVar(&helperImage, "helper-image", os.Getenv(helperImageEnv),
+		"The image providing the helper binary copied into Paper Pods, usually the image of the operator. "+
+			"Defaults to the "+helperImageEnv+" environment variable.")
+	flag.StringVar(&mojangApiUrl, "mojang-api-url", profile.DefaultApiUrl,
+		"The base URL of the Mojang API, or a stub of it, used for resolving player names to UUIDs.")
+	flag.StringVar(&mojangSessionServerUrl, "mojang-session-server-url", profile.DefaultSessionServerUrl,
+		"The base URL of the Mojang session server, or a stub of it, used for resolving player UUIDs to names.")
+	flag.StringVar(&hangarApiUrl, "hangar-api-url", plugin.DefaultHangarUrl,
+		"The base URL of the Hangar API, or a stub of it, used for resolving plugins.")
+	flag.StringVar(&modrinthApiUrl, "modrinth-api-url", plugin.DefaultModrinthUrl,
+		"The base URL of the Modrinth API, or a stub of it, used for resolving plugins.")
+	flag.StringVar(&downloaderImage, "downloader-image", reconciler.DefaultDownloaderImage,
+		"The image downloading artifacts and plugins, it must provide sh, wget, sha256sum and sha512sum.")
+	flag.StringVar(&serverImages, "server-images", "",
+		"The images running the server by major version of Java, given as \"17=image,21=image\". "+
+			"Versions not given keep their default image.")
 	opts := zap.Options{
 		Development: false,
 		TimeEncoder: zapcore.ISO8601TimeEncoder,
@@ -66,6 +117,23 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if helperImage == "" {
+		setupLog.Error(nil, "no helper image, set --helper-image or "+helperImageEnv)
+		os.Exit(1)
+	}
+
+	papermcApi, err := papermcApiFromFlags(papermcApiUrl, papermcApiCaFile, papermcApiAuthHeader)
+	if err != nil {
+		setupLog.Error(err, "invalid PaperMC API configuration")
+		os.Exit(1)
+	}
+
+	serverImagesByJava, err := serverImagesFromFlag(serverImages)
+	if err != nil {
+		setupLog.Error(err, "invalid server images")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		HealthProbeBindAddress: probeAddr,
@@ -89,12 +157,40 @@ func main() {
 	}
 
 	if err = (&controllers.PaperController{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("papermc-operator"),
+		Options: reconciler.Options{
+			PapermcApi:      papermcApi,
+			HelperImage:     helperImage,
+			DownloaderImage: downloaderImage,
+			ServerImages:    serverImagesByJava,
+			ProfileResolver: profile.NewCachingResolver(
+				profile.NewMojangResolver(profile.WithApiUrl(mojangApiUrl), profile.WithSessionServerUrl(mojangSessionServerUrl)),
+				profileCacheTTL,
+			),
+			HangarSource:   plugin.NewHangarSource(plugin.WithBaseUrl(hangarApiUrl)),
+			ModrinthSource: plugin.NewModrinthSource(plugin.WithBaseUrl(modrinthApiUrl)),
+		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Paper")
 		os.Exit(1)
 	}
+	if err = (&controllers.PaperCommandController{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("papermc-operator"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PaperCommand")
+		os.Exit(1)
+	}
+	if err = (&controllers.ServerPingController{
+		Client: mgr.GetClient(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ServerPing")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -112,3 +208,49 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// papermcApiFromFlags validates the manager wide PaperMC API, the Paper reconciler creates its clients from it.
+func papermcApiFromFlags(url string, caFile string, authHeader string) (reconciler.PapermcApi, error) {
+	api := reconciler.PapermcApi{Url: url, AuthHeader: authHeader}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return api, err
+		}
+		if _, err := papermc.NewCertPool(pem); err != nil {
+			return api, err
+		}
+		api.CaBundle = pem
+	}
+
+	if authHeader != "" {
+		if _, _, err := papermc.ParseHeader(authHeader); err != nil {
+			return api, err
+		}
+	}
+
+	return api, nil
+}
+
+// serverImagesFromFlag parses images by major version of Java, given as "17=image,21=image", onto the default images.
+func serverImagesFromFlag(value string) (map[int32]string, error) {
+	images := map[int32]string{}
+	for java, image := range reconciler.DefaultServerImages {
+		images[java] = image
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		java, image, found := strings.Cut(entry, "=")
+		version, err := strconv.ParseInt(strings.TrimSpace(java), 10, 32)
+		if !found || err != nil || version < 1 || strings.TrimSpace(image) == "" {
+			return nil, fmt.Errorf("invalid server image %q, expected <java version>=<image>", entry)
+		}
+		images[int32(version)] = strings.TrimSpace(image)
+	}
+
+	return images, nil
+}
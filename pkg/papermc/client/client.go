@@ -3,10 +3,34 @@ package client
 // Client is the client for interacting with the Paper MC API. Only minimal functionality is provided for downloading
 // new versions of Paper.
 type Client interface {
+	// GetVersions lists all versions of Paper, oldest first.
+	GetVersions() ([]string, error)
+
+	// GetBuildsForVersion lists all builds of a given version along with their release channel, oldest first.
+	GetBuildsForVersion(version string) ([]Build, error)
+
 	// GetBuildForVersion determines the build id for a given version.
 	GetBuildForVersion(version string) (int, error)
 
 	// GetUrlForVersionBuildDownload determines the download URL of a given version/build. The URL returned points
 	// to the corresponding JAR file.
 	GetUrlForVersionBuildDownload(version string, build int) (string, error)
+
+	// GetSha256ForVersionBuildDownload determines the SHA-256 checksum of the JAR file of a given version/build, as
+	// published by the API.
+	GetSha256ForVersionBuildDownload(version string, build int) (string, error)
 }
+
+// Build is a build of a Paper version.
+type Build struct {
+	// Build is the build id.
+	Build int `json:"build"`
+
+	// Channel is the release channel of the build, either ChannelDefault or ChannelExperimental.
+	Channel string `json:"channel"`
+}
+
+const (
+	ChannelDefault      = "default"
+	ChannelExperimental = "experimental"
+)
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Option configures a client created by NewPapermcClient.
+type Option func(c *papermcClient)
+
+// WithBaseUrl points the client to another API, e.g. an internal mirror. Download URLs are built from the same base.
+func WithBaseUrl(url string) Option {
+	return func(c *papermcClient) {
+		c.baseUrl = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithHttpClient replaces the http.Client used for requests.
+func WithHttpClient(client *http.Client) Option {
+	return func(c *papermcClient) {
+		c.Client = client
+	}
+}
+
+// WithRootCAs restricts the certificate authorities trusted for requests to the given pool.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *papermcClient) {
+		c.rootCAs = pool
+	}
+}
+
+// WithHeader adds a header, e.g. for authorization, to each request.
+func WithHeader(name string, value string) Option {
+	return func(c *papermcClient) {
+		c.headers.Set(name, value)
+	}
+}
+
+// NewCertPool creates a pool from PEM encoded certificates, suitable for WithRootCAs.
+func NewCertPool(pem []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no valid certificate found in CA bundle")
+	}
+	return pool, nil
+}
+
+// ParseHeader splits a header given as "Name: value", suitable for WithHeader.
+func ParseHeader(header string) (string, string, error) {
+	name, value, found := strings.Cut(header, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return "", "", fmt.Errorf("header must be given as \"Name: value\"")
+	}
+	return strings.TrimSpace(name), strings.TrimSpace(value), nil
+}
+
+func (c *papermcClient) applyRootCAs() {
+	if c.rootCAs == nil {
+		return
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if t, ok := c.Client.Transport.(*http.Transport); ok {
+		transport = t.Clone()
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.RootCAs = c.rootCAs
+
+	c.Client = &http.Client{
+		Transport:     transport,
+		CheckRedirect: c.Client.CheckRedirect,
+		Jar:           c.Client.Jar,
+		Timeout:       c.Client.Timeout,
+	}
+}
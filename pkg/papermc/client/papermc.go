@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,16 +12,63 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-func NewPapermcClient(ctx context.Context) Client {
-	return &papermcClient{
-		Client: http.DefaultClient,
-		Logger: log.FromContext(ctx),
+func NewPapermcClient(ctx context.Context, opts ...Option) Client {
+	c := &papermcClient{
+		Client:  http.DefaultClient,
+		Logger:  log.FromContext(ctx),
+		baseUrl: DefaultApiUrl,
+		headers: http.Header{},
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.applyRootCAs()
+
+	return c
 }
 
 type papermcClient struct {
 	*http.Client
 	logr.Logger
+
+	baseUrl string
+	headers http.Header
+	rootCAs *x509.CertPool
+}
+
+func (c *papermcClient) GetVersions() ([]string, error) {
+	response := struct {
+		Versions []string `json:"versions"`
+	}{}
+
+	err := c.doRequestAndUnmarshal(buildProjectDetailsUrl(c.baseUrl), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Versions) == 0 {
+		return nil, fmt.Errorf("no version found")
+	}
+
+	return response.Versions, nil
+}
+
+func (c *papermcClient) GetBuildsForVersion(version string) ([]Build, error) {
+	response := struct {
+		Builds []Build `json:"builds"`
+	}{}
+
+	err := c.doRequestAndUnmarshal(buildVersionBuildsUrl(c.baseUrl, version), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Builds) == 0 {
+		return nil, fmt.Errorf("no build found")
+	}
+
+	return response.Builds, nil
 }
 
 func (c *papermcClient) GetBuildForVersion(version string) (int, error) {
@@ -28,7 +76,7 @@ func (c *papermcClient) GetBuildForVersion(version string) (int, error) {
 		Builds []int `json:"builds"`
 	}{}
 
-	err := c.doRequestAndUnmarshal(buildVersionDetailsUrl(version), &response)
+	err := c.doRequestAndUnmarshal(buildVersionDetailsUrl(c.baseUrl, version), &response)
 	if err != nil {
 		return 0, err
 	}
@@ -41,30 +89,45 @@ func (c *papermcClient) GetBuildForVersion(version string) (int, error) {
 }
 
 func (c *papermcClient) GetUrlForVersionBuildDownload(version string, build int) (string, error) {
-	artifact, err := c.getArtifactNameForVersionAndBuild(version, build)
+	artifact, err := c.getArtifactForVersionAndBuild(version, build)
+	if err != nil {
+		return "", err
+	}
+
+	return buildVersionBuildArtifactDownloadUrl(c.baseUrl, version, build, artifact.Name), nil
+}
+
+func (c *papermcClient) GetSha256ForVersionBuildDownload(version string, build int) (string, error) {
+	artifact, err := c.getArtifactForVersionAndBuild(version, build)
 	if err != nil {
 		return "", err
 	}
 
-	return buildVersionBuildArtifactDownloadUrl(version, build, artifact), nil
+	if artifact.Sha256 == "" {
+		return "", fmt.Errorf("no checksum published for version %s build %d", version, build)
+	}
+
+	return artifact.Sha256, nil
 }
 
-func (c *papermcClient) getArtifactNameForVersionAndBuild(version string, build int) (string, error) {
+type artifact struct {
+	Name   string `json:"name"`
+	Sha256 string `json:"sha256"`
+}
+
+func (c *papermcClient) getArtifactForVersionAndBuild(version string, build int) (*artifact, error) {
 	response := struct {
 		Downloads struct {
-			Application struct {
-				Name   string `json:"name"`
-				Sha265 string `json:"sha265"`
-			} `json:"application"`
+			Application artifact `json:"application"`
 		} `json:"downloads"`
 	}{}
 
-	err := c.doRequestAndUnmarshal(buildVersionBuildDetailsUrl(version, build), &response)
+	err := c.doRequestAndUnmarshal(buildVersionBuildDetailsUrl(c.baseUrl, version, build), &response)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return response.Downloads.Application.Name, nil
+	return &response.Downloads.Application, nil
 }
 
 func (c *papermcClient) doRequestAndUnmarshal(url string, structuredResponse interface{}) error {
@@ -87,6 +150,9 @@ func (c *papermcClient) doRequest(url string) (*http.Response, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize http request: %s", err)
 	}
+	for name, values := range c.headers {
+		request.Header[name] = values
+	}
 
 	c.Logger.V(2).Info("PaperMC API request", "url", request.URL.String())
 
@@ -5,23 +5,36 @@ import (
 )
 
 const (
-	paperApiUrl           = "https://api.papermc.io"
+	// DefaultApiUrl is the base URL of the public PaperMC API.
+	DefaultApiUrl = "https://api.papermc.io"
+
+	paperProjectEndpoint  = "/v2/projects/paper"
 	paperVersionEndpoint  = "/v2/projects/paper/versions/%s"
+	paperBuildsEndpoint   = "/v2/projects/paper/versions/%s/builds"
 	paperBuildEndpoint    = "/v2/projects/paper/versions/%s/builds/%d"
 	paperDownloadEndpoint = "/v2/projects/paper/versions/%s/builds/%d/downloads/%s"
 )
 
-func buildVersionDetailsUrl(version string) string {
+func buildProjectDetailsUrl(baseUrl string) string {
+	return fmt.Sprintf("%s%s", baseUrl, paperProjectEndpoint)
+}
+
+func buildVersionDetailsUrl(baseUrl string, version string) string {
 	endpoint := fmt.Sprintf(paperVersionEndpoint, version)
-	return fmt.Sprintf("%s%s", paperApiUrl, endpoint)
+	return fmt.Sprintf("%s%s", baseUrl, endpoint)
+}
+
+func buildVersionBuildsUrl(baseUrl string, version string) string {
+	endpoint := fmt.Sprintf(paperBuildsEndpoint, version)
+	return fmt.Sprintf("%s%s", baseUrl, endpoint)
 }
 
-func buildVersionBuildDetailsUrl(version string, build int) string {
+func buildVersionBuildDetailsUrl(baseUrl string, version string, build int) string {
 	endpoint := fmt.Sprintf(paperBuildEndpoint, version, build)
-	return fmt.Sprintf("%s%s", paperApiUrl, endpoint)
+	return fmt.Sprintf("%s%s", baseUrl, endpoint)
 }
 
-func buildVersionBuildArtifactDownloadUrl(version string, build int, artifact string) string {
+func buildVersionBuildArtifactDownloadUrl(baseUrl string, version string, build int, artifact string) string {
 	endpoint := fmt.Sprintf(paperDownloadEndpoint, version, build, artifact)
-	return fmt.Sprintf("%s%s", paperApiUrl, endpoint)
+	return fmt.Sprintf("%s%s", baseUrl, endpoint)
 }
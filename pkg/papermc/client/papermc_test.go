@@ -2,13 +2,49 @@ package client
 
 import (
 	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-const version = "1.19.2"
+const (
+	version  = "1.19.2"
+	build    = 307
+	checksum = "ee8ed3bb5b7a8df1a7f1b0e4c0c4c1ebe9c2b1a1a8a0f4e1f50c1e4e4e7c0f0a"
+)
+
+// newPaperApi serves the subset of the PaperMC API used by the client. If header is given, requests without it are
+// rejected.
+func newPaperApi(header string, value string) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(paperProjectEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"project_id":"paper","versions":["1.19","1.19.1","%s"]}`, version)
+	})
+	mux.HandleFunc(fmt.Sprintf(paperBuildsEndpoint, version), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"project_id":"paper","version":"%s","builds":[{"build":305,"channel":"default"},{"build":306,"channel":"default"},{"build":%d,"channel":"experimental"}]}`, version, build)
+	})
+	mux.HandleFunc(fmt.Sprintf(paperVersionEndpoint, version), func(w http.ResponseWriter, r *http.Request) {
+		if header != "" && r.Header.Get(header) != value {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"project_id":"paper","version":"%s","builds":[305,306,%d]}`, version, build)
+	})
+	mux.HandleFunc(fmt.Sprintf(paperBuildEndpoint, version, build), func(w http.ResponseWriter, r *http.Request) {
+		if header != "" && r.Header.Get(header) != value {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"build":%d,"channel":"default","downloads":{"application":{"name":"paper-%s-%d.jar","sha256":"%s"}}}`,
+			build, version, build, checksum)
+	})
+	return mux
+}
 
 func TestNewClient(t *testing.T) {
 	client := NewPapermcClient(context.TODO())
@@ -17,17 +53,55 @@ func TestNewClient(t *testing.T) {
 }
 
 func TestGetCurrentBuildForVersion(t *testing.T) {
-	client := NewPapermcClient(context.TODO())
+	server := httptest.NewServer(newPaperApi("", ""))
+	defer server.Close()
+
+	client := NewPapermcClient(context.TODO(), WithBaseUrl(server.URL))
 	build, err := client.GetBuildForVersion(version)
 
 	assert.NoError(t, err)
-	assert.Greater(t, build, 0)
+	assert.Equal(t, 307, build)
 
 	t.Logf("build for version=%s: %d", version, build)
 }
 
+func TestGetVersions(t *testing.T) {
+	server := httptest.NewServer(newPaperApi("", ""))
+	defer server.Close()
+
+	client := NewPapermcClient(context.TODO(), WithBaseUrl(server.URL))
+	versions, err := client.GetVersions()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.19", "1.19.1", version}, versions)
+}
+
+func TestGetBuildsForVersion(t *testing.T) {
+	server := httptest.NewServer(newPaperApi("", ""))
+	defer server.Close()
+
+	client := NewPapermcClient(context.TODO(), WithBaseUrl(server.URL))
+	builds, err := client.GetBuildsForVersion(version)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Build{{305, ChannelDefault}, {306, ChannelDefault}, {build, ChannelExperimental}}, builds)
+}
+
+func TestGetBuildForUnknownVersion(t *testing.T) {
+	server := httptest.NewServer(newPaperApi("", ""))
+	defer server.Close()
+
+	client := NewPapermcClient(context.TODO(), WithBaseUrl(server.URL))
+	_, err := client.GetBuildForVersion("1.0.0")
+
+	assert.Error(t, err)
+}
+
 func TestGetUrlForVersionBuildDownload(t *testing.T) {
-	client := NewPapermcClient(context.TODO())
+	server := httptest.NewServer(newPaperApi("", ""))
+	defer server.Close()
+
+	client := NewPapermcClient(context.TODO(), WithBaseUrl(server.URL+"/"))
 	build, err := client.GetBuildForVersion(version)
 
 	require.NoError(t, err)
@@ -36,7 +110,74 @@ func TestGetUrlForVersionBuildDownload(t *testing.T) {
 	url, err := client.GetUrlForVersionBuildDownload(version, build)
 
 	assert.NoError(t, err)
-	assert.NotEmpty(t, url)
+	assert.Equal(t, server.URL+"/v2/projects/paper/versions/1.19.2/builds/307/downloads/paper-1.19.2-307.jar", url)
 
 	t.Logf("download url for version=%s build=%d: %s", version, build, url)
 }
+
+func TestGetSha256ForVersionBuildDownload(t *testing.T) {
+	server := httptest.NewServer(newPaperApi("", ""))
+	defer server.Close()
+
+	client := NewPapermcClient(context.TODO(), WithBaseUrl(server.URL))
+	build, err := client.GetBuildForVersion(version)
+
+	require.NoError(t, err)
+	require.Greater(t, build, 0)
+
+	sha256, err := client.GetSha256ForVersionBuildDownload(version, build)
+
+	assert.NoError(t, err)
+	assert.Equal(t, checksum, sha256)
+
+	t.Logf("sha256 for version=%s build=%d: %s", version, build, sha256)
+}
+
+func TestWithHeader(t *testing.T) {
+	server := httptest.NewServer(newPaperApi("Authorization", "Bearer secret"))
+	defer server.Close()
+
+	_, err := NewPapermcClient(context.TODO(), WithBaseUrl(server.URL)).GetBuildForVersion(version)
+	assert.Error(t, err)
+
+	name, value, err := ParseHeader("Authorization: Bearer secret")
+	require.NoError(t, err)
+
+	build, err := NewPapermcClient(context.TODO(), WithBaseUrl(server.URL), WithHeader(name, value)).GetBuildForVersion(version)
+	assert.NoError(t, err)
+	assert.Equal(t, 307, build)
+}
+
+func TestWithRootCAs(t *testing.T) {
+	server := httptest.NewTLSServer(newPaperApi("", ""))
+	defer server.Close()
+
+	_, err := NewPapermcClient(context.TODO(), WithBaseUrl(server.URL)).GetBuildForVersion(version)
+	assert.Error(t, err)
+
+	pool, err := NewCertPool(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	require.NoError(t, err)
+
+	build, err := NewPapermcClient(context.TODO(), WithBaseUrl(server.URL), WithRootCAs(pool)).GetBuildForVersion(version)
+	assert.NoError(t, err)
+	assert.Equal(t, 307, build)
+}
+
+func TestWithHttpClient(t *testing.T) {
+	server := httptest.NewTLSServer(newPaperApi("", ""))
+	defer server.Close()
+
+	build, err := NewPapermcClient(context.TODO(), WithBaseUrl(server.URL), WithHttpClient(server.Client())).GetBuildForVersion(version)
+	assert.NoError(t, err)
+	assert.Equal(t, 307, build)
+}
+
+func TestNewCertPoolInvalid(t *testing.T) {
+	_, err := NewCertPool([]byte("invalid"))
+	assert.Error(t, err)
+}
+
+func TestParseHeaderInvalid(t *testing.T) {
+	_, _, err := ParseHeader("Bearer secret")
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+const (
+	// DefaultHangarUrl is the base URL of the public Hangar API.
+	DefaultHangarUrl = "https://hangar.papermc.io"
+
+	hangarLatestReleaseEndpoint = "/api/v1/projects/%s/latestrelease"
+	hangarVersionEndpoint       = "/api/v1/projects/%s/versions/%s"
+
+	hangarPlatform = "PAPER"
+)
+
+// NewHangarSource creates a source resolving projects hosted by Hangar, the plugin repository of PaperMC. The latest
+// release is resolved regardless of the version of the server.
+func NewHangarSource(opts ...Option) Source {
+	return &hangarSource{httpClient: newHttpClient(DefaultHangarUrl, opts...)}
+}
+
+type hangarSource struct {
+	httpClient
+}
+
+func (s *hangarSource) Resolve(ctx context.Context, project string, version string, _ string) (Artifact, error) {
+	if version == "" {
+		if err := s.get(ctx, fmt.Sprintf(hangarLatestReleaseEndpoint, url.PathEscape(project)), &version); err != nil {
+			return Artifact{}, err
+		}
+	}
+
+	response := struct {
+		Name      string `json:"name"`
+		Downloads map[string]struct {
+			FileInfo *struct {
+				Sha256Hash string `json:"sha256Hash"`
+			} `json:"fileInfo"`
+			DownloadUrl string `json:"downloadUrl"`
+		} `json:"downloads"`
+	}{}
+	if err := s.get(ctx, fmt.Sprintf(hangarVersionEndpoint, url.PathEscape(project), url.PathEscape(version)), &response); err != nil {
+		return Artifact{}, err
+	}
+
+	download, ok := response.Downloads[hangarPlatform]
+	if !ok {
+		return Artifact{}, fmt.Errorf("%w: version %s of %s has no download for Paper", ErrNotFound, version, project)
+	}
+	if download.FileInfo == nil || download.DownloadUrl == "" {
+		// external downloads come without checksum
+		return Artifact{}, fmt.Errorf("version %s of %s is hosted externally and cannot be verified, use a url source instead", version, project)
+	}
+
+	return Artifact{
+		Version:   response.Name,
+		Url:       download.DownloadUrl,
+		Algorithm: "sha256",
+		Checksum:  download.FileInfo.Sha256Hash,
+	}, nil
+}
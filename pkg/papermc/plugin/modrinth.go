@@ -0,0 +1,93 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+const (
+	// DefaultModrinthUrl is the base URL of the public Modrinth API.
+	DefaultModrinthUrl = "https://api.modrinth.com"
+
+	modrinthVersionsEndpoint = "/v2/project/%s/version?%s"
+	modrinthVersionEndpoint  = "/v2/project/%s/version/%s"
+)
+
+// modrinthLoaders are the loaders of plugins running on Paper.
+var modrinthLoaders = []string{"paper", "spigot", "bukkit"}
+
+// NewModrinthSource creates a source resolving projects hosted by Modrinth. The latest release is resolved among the
+// versions compatible with the version of the server.
+func NewModrinthSource(opts ...Option) Source {
+	return &modrinthSource{httpClient: newHttpClient(DefaultModrinthUrl, opts...)}
+}
+
+type modrinthSource struct {
+	httpClient
+}
+
+type modrinthVersion struct {
+	VersionNumber string `json:"version_number"`
+	VersionType   string `json:"version_type"`
+	Files         []struct {
+		Url     string            `json:"url"`
+		Primary bool              `json:"primary"`
+		Hashes  map[string]string `json:"hashes"`
+	} `json:"files"`
+}
+
+func (s *modrinthSource) Resolve(ctx context.Context, project string, version string, gameVersion string) (Artifact, error) {
+	var selected *modrinthVersion
+	if version != "" {
+		selected = &modrinthVersion{}
+		if err := s.get(ctx, fmt.Sprintf(modrinthVersionEndpoint, url.PathEscape(project), url.PathEscape(version)), selected); err != nil {
+			return Artifact{}, err
+		}
+	} else {
+		loaders, _ := json.Marshal(modrinthLoaders)
+		gameVersions, _ := json.Marshal([]string{gameVersion})
+		query := url.Values{
+			"loaders":       []string{string(loaders)},
+			"game_versions": []string{string(gameVersions)},
+		}
+
+		var versions []modrinthVersion
+		if err := s.get(ctx, fmt.Sprintf(modrinthVersionsEndpoint, url.PathEscape(project), query.Encode()), &versions); err != nil {
+			return Artifact{}, err
+		}
+		// newest first
+		for i := range versions {
+			if versions[i].VersionType == "release" {
+				selected = &versions[i]
+				break
+			}
+		}
+		if selected == nil {
+			return Artifact{}, fmt.Errorf("%w: %s has no release for Paper %s", ErrNotFound, project, gameVersion)
+		}
+	}
+
+	if len(selected.Files) == 0 {
+		return Artifact{}, fmt.Errorf("%w: version %s of %s has no files", ErrNotFound, selected.VersionNumber, project)
+	}
+	file := selected.Files[0]
+	for _, f := range selected.Files {
+		if f.Primary {
+			file = f
+			break
+		}
+	}
+
+	if file.Hashes["sha512"] == "" {
+		return Artifact{}, fmt.Errorf("version %s of %s has no checksum and cannot be verified", selected.VersionNumber, project)
+	}
+
+	return Artifact{
+		Version:   selected.VersionNumber,
+		Url:       file.Url,
+		Algorithm: "sha512",
+		Checksum:  file.Hashes["sha512"],
+	}, nil
+}
@@ -0,0 +1,98 @@
+// Package plugin resolves plugins hosted by plugin repositories to verifiable downloads.
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrNotFound is returned if the project or version does not exist, or has no download for the server.
+var ErrNotFound = errors.New("plugin not found")
+
+// Artifact is a download of a plugin.
+type Artifact struct {
+	Version string
+	Url     string
+	// Algorithm of the checksum, either sha256 or sha512.
+	Algorithm string
+	Checksum  string
+}
+
+// Source resolves a version of a project to a download. The latest release compatible with the given version of the
+// server is resolved if no version is given.
+type Source interface {
+	Resolve(ctx context.Context, project string, version string, gameVersion string) (Artifact, error)
+}
+
+// Option configures a source created by NewHangarSource or NewModrinthSource.
+type Option func(c *httpClient)
+
+// WithBaseUrl points the source to another API, e.g. a stub or a mirror.
+func WithBaseUrl(url string) Option {
+	return func(c *httpClient) {
+		c.baseUrl = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithHttpClient replaces the http.Client used for requests.
+func WithHttpClient(client *http.Client) Option {
+	return func(c *httpClient) {
+		c.client = client
+	}
+}
+
+type httpClient struct {
+	client  *http.Client
+	baseUrl string
+}
+
+func newHttpClient(baseUrl string, opts ...Option) httpClient {
+	c := httpClient{
+		client:  http.DefaultClient,
+		baseUrl: baseUrl,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// get requests the given endpoint, unmarshalling JSON into v or, if v is a *string, returning the raw body.
+func (c httpClient) get(ctx context.Context, endpoint string, v interface{}) error {
+	url := c.baseUrl + endpoint
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	// repositories ask clients to identify themselves
+	req.Header.Set("User-Agent", "baichinger/papermc-operator")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+
+	if s, ok := v.(*string); ok {
+		raw, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		*s = strings.TrimSpace(string(raw))
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sha256Sum = "8c52b4b2e5a8f6c9f2a1d7a3f4b0e6d5c3a2b1f0e9d8c7b6a5f4e3d2c1b0a9f8"
+	sha512Sum = "f5b1c8d9e4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6"
+)
+
+// newHangarApi serves the subset of the Hangar API used by the source, knowing the project LuckPerms only.
+func newHangarApi() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf(hangarLatestReleaseEndpoint, "LuckPerms"), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "5.4.102\n")
+	})
+	mux.HandleFunc(fmt.Sprintf(hangarVersionEndpoint, "LuckPerms", "5.4.102"), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"name":"5.4.102","downloads":{"PAPER":{"fileInfo":{"name":"LuckPerms-Bukkit-5.4.102.jar","sha256Hash":"%s"},"externalUrl":null,"downloadUrl":"https://hangarcdn.papermc.io/plugins/Luck/LuckPerms/versions/5.4.102/PAPER/LuckPerms-Bukkit-5.4.102.jar"}}}`, sha256Sum)
+	})
+	mux.HandleFunc(fmt.Sprintf(hangarVersionEndpoint, "LuckPerms", "5.4.0"), func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"name":"5.4.0","downloads":{"PAPER":{"fileInfo":null,"externalUrl":"https://example.com/LuckPerms.jar","downloadUrl":null}}}`)
+	})
+	return mux
+}
+
+// newModrinthApi serves the subset of the Modrinth API used by the source, knowing the project chunky only.
+func newModrinthApi() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/project/chunky/version", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("game_versions") != `["1.19.2"]` || r.URL.Query().Get("loaders") != `["paper","spigot","bukkit"]` {
+			_, _ = fmt.Fprint(w, `[]`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `[
+			{"version_number":"1.3.53","version_type":"beta","files":[{"url":"https://cdn.modrinth.com/chunky-1.3.53.jar","primary":true,"hashes":{"sha512":"%[1]s"}}]},
+			{"version_number":"1.3.52","version_type":"release","files":[{"url":"https://cdn.modrinth.com/chunky-1.3.52-sources.jar","primary":false,"hashes":{"sha512":"00"}},{"url":"https://cdn.modrinth.com/chunky-1.3.52.jar","primary":true,"hashes":{"sha512":"%[1]s"}}]}
+		]`, sha512Sum)
+	})
+	mux.HandleFunc("/v2/project/chunky/version/1.3.38", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"version_number":"1.3.38","version_type":"release","files":[{"url":"https://cdn.modrinth.com/chunky-1.3.38.jar","primary":true,"hashes":{"sha512":"%s"}}]}`, sha512Sum)
+	})
+	return mux
+}
+
+func TestHangarSource(t *testing.T) {
+	server := httptest.NewServer(newHangarApi())
+	defer server.Close()
+
+	source := NewHangarSource(WithBaseUrl(server.URL))
+
+	artifact, err := source.Resolve(context.TODO(), "LuckPerms", "", "1.19.2")
+	require.NoError(t, err)
+	assert.Equal(t, Artifact{
+		Version:   "5.4.102",
+		Url:       "https://hangarcdn.papermc.io/plugins/Luck/LuckPerms/versions/5.4.102/PAPER/LuckPerms-Bukkit-5.4.102.jar",
+		Algorithm: "sha256",
+		Checksum:  sha256Sum,
+	}, artifact)
+
+	_, err = source.Resolve(context.TODO(), "LuckPerms", "5.4.0", "1.19.2")
+	assert.Error(t, err)
+
+	_, err = source.Resolve(context.TODO(), "unknown", "", "1.19.2")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestModrinthSource(t *testing.T) {
+	server := httptest.NewServer(newModrinthApi())
+	defer server.Close()
+
+	source := NewModrinthSource(WithBaseUrl(server.URL + "/"))
+
+	artifact, err := source.Resolve(context.TODO(), "chunky", "", "1.19.2")
+	require.NoError(t, err)
+	assert.Equal(t, Artifact{
+		Version:   "1.3.52",
+		Url:       "https://cdn.modrinth.com/chunky-1.3.52.jar",
+		Algorithm: "sha512",
+		Checksum:  sha512Sum,
+	}, artifact)
+
+	artifact, err = source.Resolve(context.TODO(), "chunky", "1.3.38", "1.19.2")
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.modrinth.com/chunky-1.3.38.jar", artifact.Url)
+
+	_, err = source.Resolve(context.TODO(), "chunky", "", "1.8.8")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = source.Resolve(context.TODO(), "unknown", "1.0.0", "1.19.2")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,112 @@
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultApiUrl is the base URL of the public Mojang API, resolving names.
+	DefaultApiUrl = "https://api.mojang.com"
+	// DefaultSessionServerUrl is the base URL of the public Mojang session server, resolving UUIDs.
+	DefaultSessionServerUrl = "https://sessionserver.mojang.com"
+
+	nameEndpoint = "/users/profiles/minecraft/%s"
+	uuidEndpoint = "/session/minecraft/profile/%s"
+)
+
+// Option configures a resolver created by NewMojangResolver.
+type Option func(r *mojangResolver)
+
+// WithApiUrl points the resolver to another API resolving names, e.g. a stub.
+func WithApiUrl(url string) Option {
+	return func(r *mojangResolver) {
+		r.apiUrl = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithSessionServerUrl points the resolver to another session server resolving UUIDs, e.g. a stub.
+func WithSessionServerUrl(url string) Option {
+	return func(r *mojangResolver) {
+		r.sessionServerUrl = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithHttpClient replaces the http.Client used for requests.
+func WithHttpClient(client *http.Client) Option {
+	return func(r *mojangResolver) {
+		r.client = client
+	}
+}
+
+// NewMojangResolver creates a resolver using the Mojang API, as servers in online mode do.
+func NewMojangResolver(opts ...Option) Resolver {
+	r := &mojangResolver{
+		client:           http.DefaultClient,
+		apiUrl:           DefaultApiUrl,
+		sessionServerUrl: DefaultSessionServerUrl,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+type mojangResolver struct {
+	client           *http.Client
+	apiUrl           string
+	sessionServerUrl string
+}
+
+func (r *mojangResolver) ByName(ctx context.Context, name string) (Profile, error) {
+	return r.get(ctx, r.apiUrl+fmt.Sprintf(nameEndpoint, url.PathEscape(name)))
+}
+
+func (r *mojangResolver) ByUUID(ctx context.Context, uuid string) (Profile, error) {
+	normalized, err := NormalizeUUID(uuid)
+	if err != nil {
+		return Profile{}, err
+	}
+	return r.get(ctx, r.sessionServerUrl+fmt.Sprintf(uuidEndpoint, strings.ReplaceAll(normalized, "-", "")))
+}
+
+func (r *mojangResolver) get(ctx context.Context, url string) (Profile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return Profile{}, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotFound:
+		return Profile{}, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return Profile{}, fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+
+	response := struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return Profile{}, err
+	}
+
+	uuid, err := NormalizeUUID(response.Id)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	return Profile{UUID: uuid, Name: response.Name}, nil
+}
@@ -0,0 +1,141 @@
+// Package profile resolves Minecraft player profiles, i.e. names and UUIDs, as needed for ops, whitelist and bans.
+package profile
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned if no player with the given name or UUID exists.
+var ErrNotFound = errors.New("profile not found")
+
+// Profile identifies a player.
+type Profile struct {
+	// UUID is given in its dashed form, as written by the server.
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+// Resolver resolves the profile of a player by name or by UUID.
+type Resolver interface {
+	ByName(ctx context.Context, name string) (Profile, error)
+	ByUUID(ctx context.Context, uuid string) (Profile, error)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+
+// NormalizeUUID returns the dashed, lower case form of a UUID given with or without dashes.
+func NormalizeUUID(uuid string) (string, error) {
+	if !uuidPattern.MatchString(uuid) {
+		return "", fmt.Errorf("invalid UUID %q", uuid)
+	}
+	s := strings.ToLower(strings.ReplaceAll(uuid, "-", ""))
+	return fmt.Sprintf("%s-%s-%s-%s-%s", s[0:8], s[8:12], s[12:16], s[16:20], s[20:32]), nil
+}
+
+// OfflineUUID computes the UUID the server assigns to a player when not in online mode.
+func OfflineUUID(name string) string {
+	sum := md5.Sum([]byte("OfflinePlayer:" + name))
+	// version 3, IETF variant
+	sum[6] = sum[6]&0x0f | 0x30
+	sum[8] = sum[8]&0x3f | 0x80
+	s := fmt.Sprintf("%x", sum)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", s[0:8], s[8:12], s[12:16], s[16:20], s[20:32])
+}
+
+// NewOfflineResolver creates a resolver for servers not in online mode, it resolves names without any request.
+// Resolving UUIDs is not possible, names must be given.
+func NewOfflineResolver() Resolver {
+	return offlineResolver{}
+}
+
+type offlineResolver struct{}
+
+func (offlineResolver) ByName(_ context.Context, name string) (Profile, error) {
+	return Profile{UUID: OfflineUUID(name), Name: name}, nil
+}
+
+func (offlineResolver) ByUUID(_ context.Context, uuid string) (Profile, error) {
+	return Profile{}, fmt.Errorf("cannot resolve the name of %s in offline mode, name must be given", uuid)
+}
+
+// NewCachingResolver caches the profiles resolved by the given resolver for the given time. Failures are not cached.
+func NewCachingResolver(resolver Resolver, ttl time.Duration) Resolver {
+	return &cachingResolver{
+		resolver: resolver,
+		ttl:      ttl,
+		byName:   map[string]cacheEntry{},
+		byUUID:   map[string]cacheEntry{},
+	}
+}
+
+type cacheEntry struct {
+	profile Profile
+	expires time.Time
+}
+
+type cachingResolver struct {
+	resolver Resolver
+	ttl      time.Duration
+
+	mu     sync.Mutex
+	byName map[string]cacheEntry
+	byUUID map[string]cacheEntry
+}
+
+func (c *cachingResolver) ByName(ctx context.Context, name string) (Profile, error) {
+	key := strings.ToLower(name)
+	if p, ok := c.lookup(c.byName, key); ok {
+		return p, nil
+	}
+
+	p, err := c.resolver.ByName(ctx, name)
+	if err != nil {
+		return Profile{}, err
+	}
+	c.store(p)
+	return p, nil
+}
+
+func (c *cachingResolver) ByUUID(ctx context.Context, uuid string) (Profile, error) {
+	key, err := NormalizeUUID(uuid)
+	if err != nil {
+		return Profile{}, err
+	}
+	if p, ok := c.lookup(c.byUUID, key); ok {
+		return p, nil
+	}
+
+	p, err := c.resolver.ByUUID(ctx, uuid)
+	if err != nil {
+		return Profile{}, err
+	}
+	c.store(p)
+	return p, nil
+}
+
+func (c *cachingResolver) lookup(cache map[string]cacheEntry, key string) (Profile, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := cache[key]
+	if !ok || time.Now().After(e.expires) {
+		return Profile{}, false
+	}
+	return e.profile, true
+}
+
+func (c *cachingResolver) store(p Profile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := cacheEntry{profile: p, expires: time.Now().Add(c.ttl)}
+	c.byName[strings.ToLower(p.Name)] = e
+	c.byUUID[p.UUID] = e
+}
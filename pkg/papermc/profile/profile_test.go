@@ -0,0 +1,117 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	notchId   = "069a79f444e94726a5befca90e38aaf5"
+	notchUUID = "069a79f4-44e9-4726-a5be-fca90e38aaf5"
+)
+
+// newMojangApi serves the subset of the Mojang API and session server used by the resolver, knowing Notch only.
+func newMojangApi(requests *int32) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/profiles/minecraft/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		// names are case insensitive
+		if !strings.EqualFold(r.URL.Path, fmt.Sprintf(nameEndpoint, "Notch")) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"id":"%s","name":"Notch"}`, notchId)
+	})
+	mux.HandleFunc("/session/minecraft/profile/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.URL.Path != fmt.Sprintf(uuidEndpoint, notchId) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"id":"%s","name":"Notch","properties":[]}`, notchId)
+	})
+	return mux
+}
+
+func TestMojangResolver(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(newMojangApi(&requests))
+	defer server.Close()
+
+	resolver := NewMojangResolver(WithApiUrl(server.URL), WithSessionServerUrl(server.URL+"/"))
+
+	p, err := resolver.ByName(context.TODO(), "Notch")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{UUID: notchUUID, Name: "Notch"}, p)
+
+	p, err = resolver.ByUUID(context.TODO(), notchUUID)
+	require.NoError(t, err)
+	assert.Equal(t, Profile{UUID: notchUUID, Name: "Notch"}, p)
+
+	_, err = resolver.ByName(context.TODO(), "nobody")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = resolver.ByUUID(context.TODO(), "00000000-0000-0000-0000-000000000000")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = resolver.ByUUID(context.TODO(), "not-a-uuid")
+	assert.Error(t, err)
+}
+
+func TestCachingResolver(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(newMojangApi(&requests))
+	defer server.Close()
+
+	resolver := NewCachingResolver(NewMojangResolver(WithApiUrl(server.URL), WithSessionServerUrl(server.URL)), time.Hour)
+
+	for i := 0; i < 3; i++ {
+		p, err := resolver.ByName(context.TODO(), "notch")
+		require.NoError(t, err)
+		assert.Equal(t, notchUUID, p.UUID)
+	}
+	p, err := resolver.ByUUID(context.TODO(), notchId)
+	require.NoError(t, err)
+	assert.Equal(t, "Notch", p.Name)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// failures are not cached
+	for i := 0; i < 2; i++ {
+		_, err := resolver.ByName(context.TODO(), "nobody")
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestOfflineResolver(t *testing.T) {
+	resolver := NewOfflineResolver()
+
+	p, err := resolver.ByName(context.TODO(), "Notch")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{UUID: "b50ad385-829d-3141-a216-7e7d7539ba7f", Name: "Notch"}, p)
+
+	_, err = resolver.ByUUID(context.TODO(), notchUUID)
+	assert.Error(t, err)
+}
+
+func TestNormalizeUUID(t *testing.T) {
+	uuid, err := NormalizeUUID("069A79F444E94726A5BEFCA90E38AAF5")
+	require.NoError(t, err)
+	assert.Equal(t, notchUUID, uuid)
+
+	uuid, err = NormalizeUUID(notchUUID)
+	require.NoError(t, err)
+	assert.Equal(t, notchUUID, uuid)
+
+	_, err = NormalizeUUID("069a79f4")
+	assert.Error(t, err)
+}
@@ -0,0 +1,190 @@
+// Package rcon implements a client of the RCON protocol of Minecraft servers, used to run console commands remotely.
+package rcon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	packetTypeResponse = 0
+	packetTypeCommand  = 2
+	packetTypeLogin    = 3
+
+	// maxPacketLength limits the size of packets, the server sends at most 4096 bytes of body per packet
+	maxPacketLength = 4096 + 10
+
+	// maxCommandLength is the maximum length of a command accepted by the server
+	maxCommandLength = 1446
+
+	// authFailedId is the request id of the response to a failed login
+	authFailedId = -1
+)
+
+// ErrAuthFailed is returned if the server rejects the password.
+var ErrAuthFailed = errors.New("rcon authentication failed")
+
+// Client is a connection to the RCON port of a server. It is not safe for concurrent use.
+type Client struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	nextId int32
+}
+
+// Dial connects to the server at the given address and logs in with the password.
+func Dial(ctx context.Context, address string, password string) (*Client, error) {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		conn:   conn,
+		r:      bufio.NewReader(conn),
+		nextId: 1,
+	}
+
+	if err := c.login(ctx, password); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Command runs a console command and returns its output.
+func (c *Client) Command(ctx context.Context, command string) (string, error) {
+	if len(command) > maxCommandLength {
+		return "", fmt.Errorf("command exceeds %d bytes", maxCommandLength)
+	}
+
+	defer c.watch(ctx)()
+
+	id := c.id()
+	if err := c.write(id, packetTypeCommand, command); err != nil {
+		return "", c.err(ctx, err)
+	}
+
+	// responses may be split into several packets, the response to an invalid packet sent afterwards marks the end
+	endId := c.id()
+	if err := c.write(endId, packetTypeResponse, ""); err != nil {
+		return "", c.err(ctx, err)
+	}
+
+	var out bytes.Buffer
+	for {
+		responseId, _, body, err := readPacket(c.r)
+		if err != nil {
+			return "", c.err(ctx, err)
+		}
+		switch responseId {
+		case id:
+			out.WriteString(body)
+		case endId:
+			return out.String(), nil
+		default:
+			return "", fmt.Errorf("unexpected response id %d", responseId)
+		}
+	}
+}
+
+func (c *Client) login(ctx context.Context, password string) error {
+	defer c.watch(ctx)()
+
+	id := c.id()
+	if err := c.write(id, packetTypeLogin, password); err != nil {
+		return c.err(ctx, err)
+	}
+
+	responseId, _, _, err := readPacket(c.r)
+	if err != nil {
+		return c.err(ctx, err)
+	}
+	if responseId == authFailedId {
+		return ErrAuthFailed
+	}
+	if responseId != id {
+		return fmt.Errorf("unexpected response id %d", responseId)
+	}
+
+	return nil
+}
+
+func (c *Client) id() int32 {
+	id := c.nextId
+	c.nextId++
+	return id
+}
+
+// watch applies the deadline of the context to the connection and unblocks pending reads and writes once the context
+// is done. The returned function stops watching.
+func (c *Client) watch(ctx context.Context) func() {
+	deadline, _ := ctx.Deadline()
+	_ = c.conn.SetDeadline(deadline)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// err prefers the error of the context, which caused the error of the connection if done.
+func (c *Client) err(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// write sends a packet: length, request id, type, null terminated body and an empty null terminated string.
+func (c *Client) write(id int32, packetType int32, body string) error {
+	packet := make([]byte, 0, len(body)+14)
+	packet = binary.LittleEndian.AppendUint32(packet, uint32(len(body)+10))
+	packet = binary.LittleEndian.AppendUint32(packet, uint32(id))
+	packet = binary.LittleEndian.AppendUint32(packet, uint32(packetType))
+	packet = append(packet, body...)
+	packet = append(packet, 0, 0)
+
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+func readPacket(r io.Reader) (int32, int32, string, error) {
+	var length int32
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return 0, 0, "", err
+	}
+	if length < 10 || length > maxPacketLength {
+		return 0, 0, "", fmt.Errorf("invalid packet length %d", length)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, 0, "", err
+	}
+
+	id := int32(binary.LittleEndian.Uint32(payload[0:4]))
+	packetType := int32(binary.LittleEndian.Uint32(payload[4:8]))
+	body := payload[8 : length-2]
+
+	return id, packetType, string(body), nil
+}
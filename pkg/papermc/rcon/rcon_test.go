@@ -0,0 +1,182 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const password = "secret"
+
+// fakeServer mimics the RCON implementation of Minecraft: responses are split into packets of 4096 bytes, and
+// packets of unknown type are answered with an error message. An idle server answers nothing but logins.
+type fakeServer struct {
+	address string
+
+	mu       sync.Mutex
+	commands []string
+	idle     bool
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	s := &fakeServer{address: listener.Addr().String()}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	authed := false
+	for {
+		id, packetType, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		idle := s.idle
+		s.mu.Unlock()
+
+		switch packetType {
+		case packetTypeLogin:
+			if body == password {
+				authed = true
+				_ = writeFake(conn, id, packetTypeCommand, "")
+			} else {
+				_ = writeFake(conn, authFailedId, packetTypeCommand, "")
+			}
+		case packetTypeCommand:
+			if !authed {
+				return
+			}
+			s.mu.Lock()
+			s.commands = append(s.commands, body)
+			s.mu.Unlock()
+			if idle {
+				continue
+			}
+			response := s.respond(body)
+			for len(response) > 4096 {
+				_ = writeFake(conn, id, packetTypeResponse, response[:4096])
+				response = response[4096:]
+			}
+			_ = writeFake(conn, id, packetTypeResponse, response)
+		default:
+			if idle {
+				continue
+			}
+			_ = writeFake(conn, id, packetTypeResponse, fmt.Sprintf("Unknown request %x", packetType))
+		}
+	}
+}
+
+func (s *fakeServer) respond(command string) string {
+	switch {
+	case command == "list":
+		return "There are 1 of a max of 20 players online: alice"
+	case strings.HasPrefix(command, "long"):
+		return strings.Repeat("x", 10000)
+	default:
+		return ""
+	}
+}
+
+func writeFake(conn net.Conn, id int32, packetType int32, body string) error {
+	packet := binary.LittleEndian.AppendUint32(nil, uint32(len(body)+10))
+	packet = binary.LittleEndian.AppendUint32(packet, uint32(id))
+	packet = binary.LittleEndian.AppendUint32(packet, uint32(packetType))
+	packet = append(packet, body...)
+	packet = append(packet, 0, 0)
+	_, err := conn.Write(packet)
+	return err
+}
+
+func TestCommand(t *testing.T) {
+	server := newFakeServer(t)
+
+	client, err := Dial(context.TODO(), server.address, password)
+	require.NoError(t, err)
+	defer client.Close()
+
+	out, err := client.Command(context.TODO(), "list")
+	require.NoError(t, err)
+	assert.Equal(t, "There are 1 of a max of 20 players online: alice", out)
+
+	out, err = client.Command(context.TODO(), "save-all flush")
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, []string{"list", "save-all flush"}, server.commands)
+}
+
+func TestCommandFragmented(t *testing.T) {
+	server := newFakeServer(t)
+
+	client, err := Dial(context.TODO(), server.address, password)
+	require.NoError(t, err)
+	defer client.Close()
+
+	out, err := client.Command(context.TODO(), "long")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", 10000), out)
+}
+
+func TestCommandTooLong(t *testing.T) {
+	server := newFakeServer(t)
+
+	client, err := Dial(context.TODO(), server.address, password)
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Command(context.TODO(), strings.Repeat("x", maxCommandLength+1))
+	assert.Error(t, err)
+}
+
+func TestCommandTimeout(t *testing.T) {
+	server := newFakeServer(t)
+	server.mu.Lock()
+	server.idle = true
+	server.mu.Unlock()
+
+	client, err := Dial(context.TODO(), server.address, password)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	_, err = client.Command(ctx, "list")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAuthFailed(t *testing.T) {
+	server := newFakeServer(t)
+
+	_, err := Dial(context.TODO(), server.address, "wrong")
+	assert.ErrorIs(t, err, ErrAuthFailed)
+}
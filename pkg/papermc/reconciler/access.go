@@ -0,0 +1,416 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+	"github.com/baichinger/papermc-operator/pkg/papermc/profile"
+)
+
+const (
+	configurationOps           = "ops.json"
+	configurationWhitelist     = "whitelist.json"
+	configurationBannedPlayers = "banned-players.json"
+	configurationBannedIps     = "banned-ips.json"
+
+	// annotationAppliedAccess records the access last applied to the running server, to compute the commands for
+	// applying changes
+	annotationAppliedAccess = "papermc.io/applied-access"
+
+	defaultOpPermissionLevel = 4
+
+	// accessTimeout bounds the resolution of all players of the access spec
+	accessTimeout = 30 * time.Second
+
+	// banTimeFormat is the format of timestamps in the ban lists of the server
+	banTimeFormat = "2006-01-02 15:04:05 -0700"
+	banSource     = "papermc-operator"
+	banForever    = "forever"
+)
+
+// accessState is the access of the server with all players resolved, as rendered into the configuration files.
+type accessState struct {
+	Ops           []opEntry           `json:"ops,omitempty"`
+	Whitelist     []profile.Profile   `json:"whitelist,omitempty"`
+	BannedPlayers []bannedPlayerEntry `json:"bannedPlayers,omitempty"`
+	BannedIps     []bannedIpEntry     `json:"bannedIps,omitempty"`
+}
+
+type opEntry struct {
+	profile.Profile
+	Level               int32 `json:"level"`
+	BypassesPlayerLimit bool  `json:"bypassesPlayerLimit"`
+}
+
+type bannedPlayerEntry struct {
+	profile.Profile
+	Reason  string `json:"reason,omitempty"`
+	Expires string `json:"expires"`
+}
+
+type bannedIpEntry struct {
+	Ip      string `json:"ip"`
+	Reason  string `json:"reason,omitempty"`
+	Expires string `json:"expires"`
+}
+
+// offlineMode reports whether the server is not in online mode, it derives UUIDs from names then.
+func (r *Reconciler) offlineMode() bool {
+	sp := r.paper.Spec.ServerProperties
+	return sp != nil && sp.OnlineMode != nil && !*sp.OnlineMode
+}
+
+// profileResolver returns the resolver matching the mode of the server.
+func (r *Reconciler) profileResolver() profile.Resolver {
+	if r.offlineMode() {
+		return profile.NewOfflineResolver()
+	}
+	if r.options.ProfileResolver != nil {
+		return r.options.ProfileResolver
+	}
+	return profile.NewMojangResolver()
+}
+
+// resolveAccess resolves all players of the access spec. The result is nil if access is not managed.
+func (r *Reconciler) resolveAccess() (*accessState, error) {
+	access := r.paper.Spec.Access
+	if access == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, accessTimeout)
+	defer cancel()
+
+	resolver := r.profileResolver()
+	offline := r.offlineMode()
+	state := &accessState{}
+
+	defaultLevel := opPermissionLevel(r.paper)
+	for _, op := range access.Ops {
+		p, err := resolvePlayer(ctx, resolver, offline, op.PlayerRef)
+		if err != nil {
+			return nil, err
+		}
+		level := defaultLevel
+		if op.Level != nil {
+			level = *op.Level
+		}
+		state.Ops = append(state.Ops, opEntry{Profile: p, Level: level, BypassesPlayerLimit: op.BypassesPlayerLimit})
+	}
+
+	if access.Whitelist != nil {
+		for _, player := range access.Whitelist.Players {
+			p, err := resolvePlayer(ctx, resolver, offline, player)
+			if err != nil {
+				return nil, err
+			}
+			state.Whitelist = append(state.Whitelist, p)
+		}
+	}
+
+	for _, ban := range access.BannedPlayers {
+		p, err := resolvePlayer(ctx, resolver, offline, ban.PlayerRef)
+		if err != nil {
+			return nil, err
+		}
+		state.BannedPlayers = append(state.BannedPlayers, bannedPlayerEntry{Profile: p, Reason: ban.Reason, Expires: banExpires(ban.Expires)})
+	}
+
+	for _, ban := range access.BannedIps {
+		state.BannedIps = append(state.BannedIps, bannedIpEntry{Ip: ban.Ip, Reason: ban.Reason, Expires: banExpires(ban.Expires)})
+	}
+
+	return state, nil
+}
+
+// resolvePlayer completes the reference to a player, the UUID takes precedence if both are given. Servers not in online
+// mode derive the UUID from the name, so the name takes precedence for them.
+func resolvePlayer(ctx context.Context, resolver profile.Resolver, offline bool, ref papermciov1.PlayerRef) (profile.Profile, error) {
+	var p profile.Profile
+	var err error
+	if ref.UUID != "" && (!offline || ref.Name == "") {
+		p, err = resolver.ByUUID(ctx, ref.UUID)
+	} else {
+		p, err = resolver.ByName(ctx, ref.Name)
+	}
+	if errors.Is(err, profile.ErrNotFound) {
+		return profile.Profile{}, fmt.Errorf("player %s%s not found", ref.Name, ref.UUID)
+	} else if err != nil {
+		return profile.Profile{}, fmt.Errorf("failed to resolve player %s%s: %w", ref.Name, ref.UUID, err)
+	}
+	return p, nil
+}
+
+func banExpires(expires *metav1.Time) string {
+	if expires == nil {
+		return banForever
+	}
+	return expires.UTC().Format(banTimeFormat)
+}
+
+// opPermissionLevel returns the level the server grants to operators added by command.
+func opPermissionLevel(p *papermciov1.Paper) int32 {
+	if sp := p.Spec.ServerProperties; sp != nil {
+		if level, err := strconv.Atoi(sp.Additional["op-permission-level"]); err == nil {
+			return int32(level)
+		}
+	}
+	return defaultOpPermissionLevel
+}
+
+// accessData renders the configuration files of the given access. The creation of the Paper resource is used as
+// creation of bans, so the files are stable.
+func accessData(p *papermciov1.Paper, state *accessState) (map[string]string, error) {
+	created := p.CreationTimestamp.UTC().Format(banTimeFormat)
+
+	ops := make([]interface{}, 0, len(state.Ops))
+	for _, op := range state.Ops {
+		ops = append(ops, map[string]interface{}{
+			"uuid":                op.UUID,
+			"name":                op.Name,
+			"level":               op.Level,
+			"bypassesPlayerLimit": op.BypassesPlayerLimit,
+		})
+	}
+
+	whitelist := make([]interface{}, 0, len(state.Whitelist))
+	for _, player := range state.Whitelist {
+		whitelist = append(whitelist, map[string]interface{}{
+			"uuid": player.UUID,
+			"name": player.Name,
+		})
+	}
+
+	bannedPlayers := make([]interface{}, 0, len(state.BannedPlayers))
+	for _, ban := range state.BannedPlayers {
+		bannedPlayers = append(bannedPlayers, map[string]interface{}{
+			"uuid":    ban.UUID,
+			"name":    ban.Name,
+			"created": created,
+			"source":  banSource,
+			"expires": ban.Expires,
+			"reason":  banReason(ban.Reason),
+		})
+	}
+
+	bannedIps := make([]interface{}, 0, len(state.BannedIps))
+	for _, ban := range state.BannedIps {
+		bannedIps = append(bannedIps, map[string]interface{}{
+			"ip":      ban.Ip,
+			"created": created,
+			"source":  banSource,
+			"expires": ban.Expires,
+			"reason":  banReason(ban.Reason),
+		})
+	}
+
+	data := map[string]string{}
+	for name, entries := range map[string][]interface{}{
+		configurationOps:           ops,
+		configurationWhitelist:     whitelist,
+		configurationBannedPlayers: bannedPlayers,
+		configurationBannedIps:     bannedIps,
+	} {
+		raw, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		data[name] = string(raw)
+	}
+
+	return data, nil
+}
+
+// banReason returns the reason the server records for bans without reason.
+func banReason(reason string) string {
+	if reason == "" {
+		return "Banned by an operator."
+	}
+	return reason
+}
+
+// accessCommands computes the console commands changing the applied access to the desired one. Changes of existing
+// entries, and entries the commands cannot express, e.g. temporary bans, require a restart of the server instead.
+func accessCommands(applied *accessState, desired *accessState, defaultLevel int32) (commands []string, restart bool) {
+	appliedOps := map[string]opEntry{}
+	for _, op := range applied.Ops {
+		appliedOps[op.UUID] = op
+	}
+	desiredOps := map[string]opEntry{}
+	for _, op := range desired.Ops {
+		desiredOps[op.UUID] = op
+		if a, ok := appliedOps[op.UUID]; ok {
+			restart = restart || a != op
+		} else if op.Level != defaultLevel || op.BypassesPlayerLimit {
+			restart = true
+		} else {
+			commands = append(commands, "op "+op.Name)
+		}
+	}
+	for _, op := range applied.Ops {
+		if _, ok := desiredOps[op.UUID]; !ok {
+			commands = append(commands, "deop "+op.Name)
+		}
+	}
+
+	appliedWhitelist := map[string]profile.Profile{}
+	for _, player := range applied.Whitelist {
+		appliedWhitelist[player.UUID] = player
+	}
+	desiredWhitelist := map[string]profile.Profile{}
+	whitelistChanged := false
+	for _, player := range desired.Whitelist {
+		desiredWhitelist[player.UUID] = player
+		if _, ok := appliedWhitelist[player.UUID]; !ok {
+			commands = append(commands, "whitelist add "+player.Name)
+			whitelistChanged = true
+		}
+	}
+	for _, player := range applied.Whitelist {
+		if _, ok := desiredWhitelist[player.UUID]; !ok {
+			commands = append(commands, "whitelist remove "+player.Name)
+			whitelistChanged = true
+		}
+	}
+	if whitelistChanged {
+		// kicks players no longer whitelisted if the whitelist is enforced
+		commands = append(commands, "whitelist reload")
+	}
+
+	appliedBannedPlayers := map[string]bannedPlayerEntry{}
+	for _, ban := range applied.BannedPlayers {
+		appliedBannedPlayers[ban.UUID] = ban
+	}
+	desiredBannedPlayers := map[string]bannedPlayerEntry{}
+	for _, ban := range desired.BannedPlayers {
+		desiredBannedPlayers[ban.UUID] = ban
+		if a, ok := appliedBannedPlayers[ban.UUID]; ok {
+			restart = restart || a != ban
+		} else if ban.Expires != banForever {
+			restart = true
+		} else {
+			commands = append(commands, withReason("ban "+ban.Name, ban.Reason))
+		}
+	}
+	for _, ban := range applied.BannedPlayers {
+		if _, ok := desiredBannedPlayers[ban.UUID]; !ok {
+			commands = append(commands, "pardon "+ban.Name)
+		}
+	}
+
+	appliedBannedIps := map[string]bannedIpEntry{}
+	for _, ban := range applied.BannedIps {
+		appliedBannedIps[ban.Ip] = ban
+	}
+	desiredBannedIps := map[string]bannedIpEntry{}
+	for _, ban := range desired.BannedIps {
+		desiredBannedIps[ban.Ip] = ban
+		if a, ok := appliedBannedIps[ban.Ip]; ok {
+			restart = restart || a != ban
+		} else if ban.Expires != banForever {
+			restart = true
+		} else {
+			commands = append(commands, withReason("ban-ip "+ban.Ip, ban.Reason))
+		}
+	}
+	for _, ban := range applied.BannedIps {
+		if _, ok := desiredBannedIps[ban.Ip]; !ok {
+			commands = append(commands, "pardon-ip "+ban.Ip)
+		}
+	}
+
+	return commands, restart
+}
+
+func withReason(command string, reason string) string {
+	if reason == "" {
+		return command
+	}
+	return command + " " + reason
+}
+
+// ReconcileAccess applies changes of the access to the running server via console commands, or restarts it if
+// changes cannot be applied by commands. The server reads the rendered files on start only.
+func (r *Reconciler) ReconcileAccess() Result {
+	if r.access == nil {
+		return newSkippedResult()
+	}
+
+	pod := corev1.Pod{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: instancePodName(r.paper)}, &pod); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+		return newSkippedResult()
+	} else if pod.DeletionTimestamp != nil || !podReady(&pod) {
+		// applied on start
+		return newSkippedResult()
+	}
+
+	desired, err := json.Marshal(r.access)
+	if err != nil {
+		return newFailedResult(err)
+	}
+	if pod.Annotations[annotationAppliedAccess] == string(desired) {
+		return newSkippedResult()
+	}
+
+	applied := &accessState{}
+	if raw, ok := pod.Annotations[annotationAppliedAccess]; ok {
+		if err := json.Unmarshal([]byte(raw), applied); err != nil {
+			return newFailedResult(fmt.Errorf("failed to read applied access: %w", err))
+		}
+	}
+
+	commands, restart := accessCommands(applied, r.access, opPermissionLevel(r.paper))
+	if restart || !hasAnnotation(&pod, annotationAppliedAccess) {
+		if r.javaIncompatible {
+			// the server would be replaced by one of the desired version, applied once it can be started
+			return newSkippedResult()
+		}
+		message := "Restarting instance, access changes cannot be applied while running"
+		r.recorder.Event(r.paper, corev1.EventTypeNormal, eventReasonRestarting, message)
+		return r.restartPaperInstance(&pod, reasonRestarting, message)
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, rconTimeout)
+	defer cancel()
+
+	rc, err := r.rconClient(ctx, &pod)
+	if err != nil {
+		return newFailedResult(fmt.Errorf("failed to apply access: %w", err))
+	}
+	defer rc.Close()
+
+	for _, command := range commands {
+		if _, err := rc.Command(ctx, command); err != nil {
+			return newFailedResult(fmt.Errorf("failed to apply access by %q: %w", command, err))
+		}
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	pod.Annotations[annotationAppliedAccess] = string(desired)
+	if err := r.client.Patch(r.ctx, &pod, patch); err != nil {
+		return newFailedResult(err)
+	}
+
+	r.recorder.Eventf(r.paper, corev1.EventTypeNormal, eventReasonAccessApplied, "Applied %d access changes by console commands", len(commands))
+
+	return newUpdatedResult()
+}
+
+func hasAnnotation(pod *corev1.Pod, annotation string) bool {
+	_, ok := pod.Annotations[annotation]
+	return ok
+}
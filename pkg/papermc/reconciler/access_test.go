@@ -0,0 +1,192 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+	"github.com/baichinger/papermc-operator/pkg/papermc/profile"
+)
+
+var (
+	alice = profile.Profile{UUID: "5b1ef0a4-6c1f-4e0c-9ad4-2d3a7c6d8e01", Name: "alice"}
+	bob   = profile.Profile{UUID: "0f3c8a2e-1b7d-4f5a-8c9e-6a4b2d1e7f02", Name: "bob"}
+)
+
+func TestAccessCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		applied  accessState
+		desired  accessState
+		commands []string
+		restart  bool
+	}{
+		{
+			name: "unchanged",
+			applied: accessState{
+				Ops:       []opEntry{{Profile: alice, Level: 4}},
+				Whitelist: []profile.Profile{alice},
+			},
+			desired: accessState{
+				Ops:       []opEntry{{Profile: alice, Level: 4}},
+				Whitelist: []profile.Profile{alice},
+			},
+		},
+		{
+			name:     "add op",
+			desired:  accessState{Ops: []opEntry{{Profile: alice, Level: 4}}},
+			commands: []string{"op alice"},
+		},
+		{
+			name:    "add op with other level",
+			desired: accessState{Ops: []opEntry{{Profile: alice, Level: 2}}},
+			restart: true,
+		},
+		{
+			name:     "remove op",
+			applied:  accessState{Ops: []opEntry{{Profile: alice, Level: 4}}},
+			commands: []string{"deop alice"},
+		},
+		{
+			name:    "change level of op",
+			applied: accessState{Ops: []opEntry{{Profile: alice, Level: 4}}},
+			desired: accessState{Ops: []opEntry{{Profile: alice, Level: 3}}},
+			restart: true,
+		},
+		{
+			name:     "add and remove whitelisted player",
+			applied:  accessState{Whitelist: []profile.Profile{alice}},
+			desired:  accessState{Whitelist: []profile.Profile{bob}},
+			commands: []string{"whitelist add bob", "whitelist remove alice", "whitelist reload"},
+		},
+		{
+			name:     "ban player",
+			desired:  accessState{BannedPlayers: []bannedPlayerEntry{{Profile: bob, Reason: "griefing", Expires: banForever}}},
+			commands: []string{"ban bob griefing"},
+		},
+		{
+			name:    "ban player temporarily",
+			desired: accessState{BannedPlayers: []bannedPlayerEntry{{Profile: bob, Expires: "2030-01-01 00:00:00 +0000"}}},
+			restart: true,
+		},
+		{
+			name:     "pardon player",
+			applied:  accessState{BannedPlayers: []bannedPlayerEntry{{Profile: bob, Expires: banForever}}},
+			commands: []string{"pardon bob"},
+		},
+		{
+			name:    "change reason of ban",
+			applied: accessState{BannedPlayers: []bannedPlayerEntry{{Profile: bob, Reason: "griefing", Expires: banForever}}},
+			desired: accessState{BannedPlayers: []bannedPlayerEntry{{Profile: bob, Reason: "cheating", Expires: banForever}}},
+			restart: true,
+		},
+		{
+			name:     "ban and pardon ip",
+			applied:  accessState{BannedIps: []bannedIpEntry{{Ip: "192.0.2.1", Expires: banForever}}},
+			desired:  accessState{BannedIps: []bannedIpEntry{{Ip: "192.0.2.2", Expires: banForever}}},
+			commands: []string{"ban-ip 192.0.2.2", "pardon-ip 192.0.2.1"},
+		},
+		{
+			name:    "ban ip temporarily",
+			desired: accessState{BannedIps: []bannedIpEntry{{Ip: "192.0.2.2", Expires: "2030-01-01 00:00:00 +0000"}}},
+			restart: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands, restart := accessCommands(&tt.applied, &tt.desired, defaultOpPermissionLevel)
+			assert.Equal(t, tt.commands, commands)
+			assert.Equal(t, tt.restart, restart)
+		})
+	}
+}
+
+// fakeResolver resolves the given profiles as the Mojang API would.
+type fakeResolver []profile.Profile
+
+func (r fakeResolver) ByName(_ context.Context, name string) (profile.Profile, error) {
+	for _, p := range r {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return profile.Profile{}, profile.ErrNotFound
+}
+
+func (r fakeResolver) ByUUID(_ context.Context, uuid string) (profile.Profile, error) {
+	for _, p := range r {
+		if p.UUID == uuid {
+			return p, nil
+		}
+	}
+	return profile.Profile{}, profile.ErrNotFound
+}
+
+func TestResolvePlayer(t *testing.T) {
+	offlineUUID := profile.OfflineUUID("alice")
+
+	tests := []struct {
+		name    string
+		offline bool
+		ref     papermciov1.PlayerRef
+		want    profile.Profile
+		err     bool
+	}{
+		{
+			name: "by name",
+			ref:  papermciov1.PlayerRef{Name: "alice"},
+			want: alice,
+		},
+		{
+			name: "by uuid",
+			ref:  papermciov1.PlayerRef{UUID: bob.UUID},
+			want: bob,
+		},
+		{
+			name: "uuid takes precedence",
+			ref:  papermciov1.PlayerRef{Name: "alice", UUID: bob.UUID},
+			want: bob,
+		},
+		{
+			name: "unknown",
+			ref:  papermciov1.PlayerRef{Name: "carol"},
+			err:  true,
+		},
+		{
+			name:    "offline by name",
+			offline: true,
+			ref:     papermciov1.PlayerRef{Name: "alice"},
+			want:    profile.Profile{UUID: offlineUUID, Name: "alice"},
+		},
+		{
+			name:    "offline by name and uuid",
+			offline: true,
+			ref:     papermciov1.PlayerRef{Name: "alice", UUID: alice.UUID},
+			want:    profile.Profile{UUID: offlineUUID, Name: "alice"},
+		},
+		{
+			name:    "offline by uuid",
+			offline: true,
+			ref:     papermciov1.PlayerRef{UUID: alice.UUID},
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resolver profile.Resolver = fakeResolver{alice, bob}
+			if tt.offline {
+				resolver = profile.NewOfflineResolver()
+			}
+			p, err := resolvePlayer(context.Background(), resolver, tt.offline, tt.ref)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, p)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package reconciler
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	papermc "github.com/baichinger/papermc-operator/pkg/papermc/client"
+)
+
+// PapermcApi is the manager wide PaperMC API, or a mirror of it, along with the TLS trust and auth header for requests
+// to it. Both stay within the operator, they are neither sent to another API nor passed on to provisioners.
+type PapermcApi struct {
+	// Url is the base URL of the API, defaults to papermc.DefaultApiUrl.
+	Url string
+
+	// CaBundle holds PEM encoded certificates trusted for requests to the API instead of the system pool, if any.
+	CaBundle []byte
+
+	// AuthHeader is sent with each request to the API, given as "Name: value", if any.
+	AuthHeader string
+}
+
+func (a PapermcApi) url() string {
+	if a.Url == "" {
+		return papermc.DefaultApiUrl
+	}
+	return a.Url
+}
+
+// managerApiAccess reports whether the TLS trust and auth header of the manager wide API apply to the Paper resource.
+// They are meant for the manager wide API only, so they are dropped once the Paper resource points to another one.
+func (r *Reconciler) managerApiAccess() bool {
+	api := r.paper.Spec.Api
+	return api == nil || api.Url == "" || strings.TrimSuffix(api.Url, "/") == strings.TrimSuffix(r.options.PapermcApi.url(), "/")
+}
+
+// withApiAccess passes the auth header of the Paper resource to the first container of the Pod, it is read from its
+// Secret into PAPERMC_API_AUTH_HEADER. The CA bundle is not passed, see papermciov1.ApiSpec.
+func (r *Reconciler) withApiAccess(pod *corev1.Pod) *corev1.Pod {
+	api := r.paper.Spec.Api
+	if api == nil || api.AuthHeader == nil {
+		return pod
+	}
+
+	container := &pod.Spec.Containers[0]
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:      "PAPERMC_API_AUTH_HEADER",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: api.AuthHeader.DeepCopy()},
+	})
+
+	return pod
+}
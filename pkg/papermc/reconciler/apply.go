@@ -0,0 +1,94 @@
+package reconciler
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// fieldManager identifies the operator as owner of the fields it applies
+	fieldManager = "papermc-operator"
+
+	annotationDesiredHash = "papermc.io/desired-hash"
+)
+
+// apply server-side applies the desired object, forcing ownership of the fields set in it. Fields not set in the
+// desired object, like node ports allocated by the cluster, are left alone.
+//
+// The hash of the desired object is recorded in an annotation. If the object changed although its desired state did
+// not, the change reverted a modification made by someone else, which is recorded as drift correction.
+func (r *Reconciler) apply(obj client.Object) Result {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return newFailedResult(err)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	if err := ctrl.SetControllerReference(r.paper, obj, r.scheme); err != nil {
+		return newFailedResult(err)
+	}
+
+	desiredHash := hashOf(obj)
+	annotations := map[string]string{annotationDesiredHash: desiredHash}
+	for k, v := range obj.GetAnnotations() {
+		annotations[k] = v
+	}
+	obj.SetAnnotations(annotations)
+
+	// read the live object, bypassing the cache, so changes caused by applying are reliably detected
+	existing, err := r.scheme.New(gvk)
+	if err != nil {
+		return newFailedResult(err)
+	}
+	existingObj := existing.(client.Object)
+	found := true
+	if err := r.reader.Get(r.ctx, client.ObjectKeyFromObject(obj), existingObj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+		found = false
+	}
+
+	if err := r.client.Patch(r.ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+		return newFailedResult(err)
+	}
+
+	if !found {
+		return newUpdatedResult()
+	}
+	if existingObj.GetResourceVersion() == obj.GetResourceVersion() {
+		// nothing to do, object is up-to-date
+		return newSkippedResult()
+	}
+	if existingObj.GetAnnotations()[annotationDesiredHash] != desiredHash {
+		// desired state changed
+		return newUpdatedResult()
+	}
+
+	return r.driftCorrected(gvk.Kind, obj.GetName())
+}
+
+// driftCorrected records the repair of an object modified by someone else.
+func (r *Reconciler) driftCorrected(kind string, name string) Result {
+	logger := log.FromContext(r.ctx)
+
+	logger.Info("drift corrected", "kind", kind, "name", name)
+	r.recorder.Eventf(r.paper, corev1.EventTypeNormal, eventReasonDriftCorrected, "%s %s was modified and restored to its desired state", kind, name)
+
+	r.paper.Status.DriftCorrections++
+	now := metav1.Now()
+	r.paper.Status.LastDriftCorrectionTimestamp = &now
+
+	if res := r.updateStatus(); res.Failed() {
+		return newFailedResult(fmt.Errorf("failed to record drift correction of %s %s: %w", kind, name, res.GetError()))
+	}
+
+	return newUpdatedResult()
+}
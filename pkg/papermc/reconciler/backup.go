@@ -0,0 +1,364 @@
+package reconciler
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+const (
+	componentBackup = "backup"
+
+	annotationBackupRecorded = "papermc.io/backup-recorded"
+	// annotationBackupUnschedulable remembers that a pending backup was reported as unschedulable
+	annotationBackupUnschedulable = "papermc.io/backup-unschedulable"
+
+	// backupActiveDeadlineSeconds gives up on a backup not completed in time, e.g. as it cannot be scheduled on the
+	// node of the server, so it does not keep the following ones from being scheduled
+	backupActiveDeadlineSeconds = 3 * 60 * 60
+	// backupStartingDeadlineSeconds skips a backup not started in time, e.g. while the CronJob controller is down,
+	// instead of catching up on it later
+	backupStartingDeadlineSeconds = 15 * 60
+
+	// jobReasonDeadlineExceeded is the reason of the Failed condition of a Job not completed within its deadline
+	jobReasonDeadlineExceeded = "DeadlineExceeded"
+
+	backupVolume    = "backup"
+	backupMountPath = "/backup"
+
+	defaultBackupRetention = 7
+)
+
+// backupResult is the termination message of a backup, see the backup command of the helper.
+type backupResult struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ReconcileBackup schedules backups of the data directory by a CronJob, and reports the last successful backup in the
+// status. Backups run on the node of the server and pause saving via RCON while archiving, so they only run while the
+// server does.
+func (r *Reconciler) ReconcileBackup() Result {
+	if r.paper.Spec.Backup == nil {
+		return r.deleteBackupCronJob()
+	}
+
+	cronJob, err := r.backupCronJob()
+	if err != nil {
+		return newFailedResult(err)
+	}
+	if res := r.apply(cronJob); res.Failed() || res.Updated() {
+		return res
+	}
+
+	return r.reconcileBackupResults()
+}
+
+// reconcileBackupResults records each finished backup once, remembering it by an annotation on its Pod. The last
+// successful backup is reported in the status. Backups which cannot be scheduled, and ones given up on as they did not
+// complete in time, are recorded as well.
+func (r *Reconciler) reconcileBackupResults() Result {
+	logger := log.FromContext(r.ctx)
+
+	pods := &corev1.PodList{}
+	if err := r.client.List(r.ctx, pods, client.InNamespace(r.paper.Namespace), client.MatchingLabels(labelsForBackup(r.paper))); err != nil {
+		return newFailedResult(err)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].CreationTimestamp.Before(&pods.Items[j].CreationTimestamp)
+	})
+
+	recorded := false
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if hasAnnotation(pod, annotationBackupRecorded) {
+			continue
+		}
+
+		annotation := annotationBackupRecorded
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+			result := backupResult{}
+			if err := json.Unmarshal([]byte(terminationMessage(pod)), &result); err != nil {
+				logger.Info("unexpected result of backup", "pod", pod.Name, "err", err)
+				break
+			}
+			r.recorder.Eventf(r.paper, corev1.EventTypeNormal, eventReasonBackupCompleted, "Backup %s completed, %d bytes", result.Name, result.Size)
+			if last := r.paper.Status.Backup; last == nil || last.LastBackupTimestamp == nil || last.LastBackupTimestamp.Time.Before(result.Timestamp) {
+				r.paper.Status.Backup = &papermciov1.BackupStatus{
+					LastBackupTimestamp: &metav1.Time{Time: result.Timestamp},
+					LastBackupName:      result.Name,
+					LastBackupSize:      result.Size,
+				}
+			}
+		case corev1.PodFailed:
+			message := terminationMessage(pod)
+			if message == "" {
+				message = podFailureReason(pod)
+			}
+			r.recorder.Eventf(r.paper, corev1.EventTypeWarning, eventReasonBackupFailed, "Backup by Pod %s failed: %s", pod.Name, message)
+		case corev1.PodPending:
+			message, unschedulable := podUnschedulable(pod)
+			if !unschedulable || hasAnnotation(pod, annotationBackupUnschedulable) {
+				continue
+			}
+			r.recorder.Eventf(r.paper, corev1.EventTypeWarning, eventReasonBackupUnschedulable, "Backup by Pod %s cannot be scheduled on the node of the server: %s", pod.Name, message)
+			annotation = annotationBackupUnschedulable
+		default:
+			continue
+		}
+
+		if err := r.annotateBackup(pod, annotation); err != nil {
+			return newFailedResult(err)
+		}
+		recorded = true
+	}
+
+	// the Pods of backups given up on are deleted, the Job is left
+	jobs := &batchv1.JobList{}
+	if err := r.client.List(r.ctx, jobs, client.InNamespace(r.paper.Namespace), client.MatchingLabels(labelsForBackup(r.paper))); err != nil {
+		return newFailedResult(err)
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if metav1.HasAnnotation(job.ObjectMeta, annotationBackupRecorded) || !jobDeadlineExceeded(job) {
+			continue
+		}
+
+		r.recorder.Eventf(r.paper, corev1.EventTypeWarning, eventReasonBackupFailed, "Backup by Job %s did not complete within %s, it was given up on", job.Name, time.Duration(backupActiveDeadlineSeconds)*time.Second)
+		if err := r.annotateBackup(job, annotationBackupRecorded); err != nil {
+			return newFailedResult(err)
+		}
+		recorded = true
+	}
+
+	if res := r.updateStatus(); res.Failed() || res.Updated() {
+		return res
+	}
+	if recorded {
+		return newUpdatedResult()
+	}
+	return newSkippedResult()
+}
+
+// annotateBackup remembers the Pod or Job of a backup as recorded by the given annotation.
+func (r *Reconciler) annotateBackup(obj client.Object, annotation string) error {
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[annotation] = "true"
+	obj.SetAnnotations(annotations)
+	if err := r.client.Patch(r.ctx, obj, patch); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// podUnschedulable reports whether the scheduler found no node for the Pod, and why.
+func podUnschedulable(pod *corev1.Pod) (string, bool) {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && c.Reason == corev1.PodReasonUnschedulable {
+			return c.Message, true
+		}
+	}
+	return "", false
+}
+
+// jobDeadlineExceeded reports whether the Job failed as it did not complete within its deadline.
+func jobDeadlineExceeded(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue && c.Reason == jobReasonDeadlineExceeded {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteBackupCronJob removes the CronJob once no backup is configured anymore, backups already stored are kept.
+func (r *Reconciler) deleteBackupCronJob() Result {
+	existingCronJob := batchv1.CronJob{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: backupObjectName(r.paper)}, &existingCronJob); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+		return newSkippedResult()
+	}
+
+	if err := r.client.Delete(r.ctx, &existingCronJob, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+		return newFailedResult(err)
+	}
+	return newUpdatedResult()
+}
+
+// backupCronJob builds the CronJob running backups, one at a time.
+func (r *Reconciler) backupCronJob() (*batchv1.CronJob, error) {
+	spec := r.paper.Spec.Backup
+
+	pod, err := r.withPodTemplate(r.backupPod(), false)
+	if err != nil {
+		return nil, err
+	}
+	// the world volume is mounted on the node of the server only
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pod.Spec.Affinity.PodAffinity == nil {
+		pod.Spec.Affinity.PodAffinity = &corev1.PodAffinity{}
+	}
+	pod.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(pod.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution, corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: selectorForServer(r.paper)},
+		TopologyKey:   corev1.LabelHostname,
+	})
+
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backupObjectName(r.paper),
+			Namespace: r.paper.Namespace,
+			Labels:    labelsForBackup(r.paper),
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   spec.Schedule,
+			TimeZone:                   spec.TimeZone,
+			StartingDeadlineSeconds:    pointer.Int64(backupStartingDeadlineSeconds),
+			Suspend:                    pointer.Bool(spec.Suspend),
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: pointer.Int32(3),
+			FailedJobsHistoryLimit:     pointer.Int32(3),
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labelsForBackup(r.paper),
+				},
+				Spec: batchv1.JobSpec{
+					// a failed backup is retried with the next one
+					BackoffLimit:          pointer.Int32(0),
+					ActiveDeadlineSeconds: pointer.Int64(backupActiveDeadlineSeconds),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels:      pod.Labels,
+							Annotations: pod.Annotations,
+						},
+						Spec: pod.Spec,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// backupPod builds the Pod archiving the data directory into the target, see the backup command of the helper.
+func (r *Reconciler) backupPod() *corev1.Pod {
+	spec := r.paper.Spec.Backup
+	retention := int32(defaultBackupRetention)
+	if spec.Retention != nil {
+		retention = *spec.Retention
+	}
+
+	command := []string{
+		fmt.Sprintf("/%s", helperBinary), "backup",
+		"--data=/app/data", fmt.Sprintf("--name=%s", r.paper.Name), fmt.Sprintf("--retention=%d", retention),
+		fmt.Sprintf("--rcon-address=%s:%d", rconServiceName(r.paper.Name), rconPort), "--work=/tmp",
+	}
+	env := []corev1.EnvVar{r.rconPasswordEnvVar()}
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "app-data",
+			MountPath: "/app/data",
+			ReadOnly:  true,
+		},
+		{
+			Name:      "tmp",
+			MountPath: "/tmp",
+		},
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: "app-data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: instanceClaimName(r.paper),
+					ReadOnly:  true,
+				},
+			},
+		},
+		{
+			Name: "tmp",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+
+	if target := spec.PersistentVolumeClaim; target != nil {
+		command = append(command, fmt.Sprintf("--dir=%s", path.Join(backupMountPath, target.Path)))
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      backupVolume,
+			MountPath: backupMountPath,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: backupVolume,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: target.ClaimName,
+				},
+			},
+		})
+	}
+	if target := spec.S3; target != nil {
+		command = append(command,
+			fmt.Sprintf("--s3-endpoint=%s", target.Endpoint), fmt.Sprintf("--s3-bucket=%s", target.Bucket),
+			fmt.Sprintf("--s3-prefix=%s", target.Prefix), fmt.Sprintf("--s3-region=%s", target.Region),
+		)
+		env = append(env,
+			corev1.EnvVar{Name: "S3_ACCESS_KEY_ID", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: target.AccessKeyId.DeepCopy()}},
+			corev1.EnvVar{Name: "S3_SECRET_ACCESS_KEY", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: target.SecretAccessKey.DeepCopy()}},
+		)
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: labelsForBackup(r.paper),
+		},
+		Spec: corev1.PodSpec{
+			AutomountServiceAccountToken: pointer.Bool(false),
+			Containers: []corev1.Container{{
+				Name:                     componentBackup,
+				Image:                    r.options.HelperImage,
+				Command:                  command,
+				Env:                      env,
+				VolumeMounts:             volumeMounts,
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				SecurityContext:          secureContainerSecurityContext(),
+			}},
+			RestartPolicy:   corev1.RestartPolicyNever,
+			SecurityContext: securePodSecurityContext(),
+			Volumes:         volumes,
+		},
+	}
+}
+
+func backupObjectName(p *papermciov1.Paper) string {
+	return fmt.Sprintf("%s-backup", p.Name)
+}
+
+// labelsForBackup labels the CronJob and the Pods running backups.
+func labelsForBackup(p *papermciov1.Paper) map[string]string {
+	labels := labelsForPaperInstance(p)
+	labels[labelComponent] = componentBackup
+	return labels
+}
@@ -0,0 +1,84 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+func TestReconcileBackupResultsReportsStuckBackups(t *testing.T) {
+	paper := &papermciov1.Paper{ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default"}}
+	pending := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "paper-backup-1-abcde", Namespace: "default", Labels: labelsForBackup(paper)},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 1 Insufficient memory, 2 node(s) didn't match pod affinity rules.",
+			}},
+		},
+	}
+	expired := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "paper-backup-0", Namespace: "default", Labels: labelsForBackup(paper)},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: jobReasonDeadlineExceeded}},
+		},
+	}
+	running := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "paper-backup-1", Namespace: "default", Labels: labelsForBackup(paper)},
+	}
+	c, scheme := newFakeClient(t, paper, pending, expired, running)
+	recorder := record.NewFakeRecorder(10)
+
+	r := NewPaperReconciler(c, c, scheme, recorder, context.Background(), paper, Options{})
+
+	res := r.reconcileBackupResults()
+	require.NoError(t, res.GetError())
+	assert.True(t, res.Updated())
+	require.Len(t, recorder.Events, 2)
+	assert.Contains(t, <-recorder.Events, eventReasonBackupUnschedulable)
+	assert.Contains(t, <-recorder.Events, eventReasonBackupFailed)
+
+	pod := &corev1.Pod{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(pending), pod))
+	assert.True(t, hasAnnotation(pod, annotationBackupUnschedulable))
+	assert.False(t, hasAnnotation(pod, annotationBackupRecorded))
+
+	// reported once only
+	res = r.reconcileBackupResults()
+	require.NoError(t, res.GetError())
+	assert.True(t, res.Skipped())
+	assert.Empty(t, recorder.Events)
+}
+
+func TestBackupCronJobDeadlines(t *testing.T) {
+	paper := &papermciov1.Paper{
+		ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default"},
+		Spec: papermciov1.PaperSpec{
+			Backup: &papermciov1.BackupSpec{
+				Schedule:              "0 4 * * *",
+				PersistentVolumeClaim: &papermciov1.BackupVolumeTarget{ClaimName: "backups"},
+			},
+		},
+	}
+	c, scheme := newFakeClient(t, paper)
+	r := NewPaperReconciler(c, c, scheme, record.NewFakeRecorder(10), context.Background(), paper, Options{})
+
+	cronJob, err := r.backupCronJob()
+	require.NoError(t, err)
+	require.NotNil(t, cronJob.Spec.StartingDeadlineSeconds)
+	assert.Equal(t, int64(backupStartingDeadlineSeconds), *cronJob.Spec.StartingDeadlineSeconds)
+	require.NotNil(t, cronJob.Spec.JobTemplate.Spec.ActiveDeadlineSeconds)
+	assert.Equal(t, int64(backupActiveDeadlineSeconds), *cronJob.Spec.JobTemplate.Spec.ActiveDeadlineSeconds)
+}
@@ -0,0 +1,181 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+// commandRetryInterval is the interval a pending PaperCommand checks again whether its server is available.
+const commandRetryInterval = 30 * time.Second
+
+type CommandReconciler struct {
+	client   client.Client
+	recorder record.EventRecorder
+	ctx      context.Context
+	command  *papermciov1.PaperCommand
+
+	// persistedStatus is the status last read or written, to detect changes
+	persistedStatus *papermciov1.PaperCommandStatus
+}
+
+// NewCommandReconciler creates a reconciler for the given PaperCommand resource.
+func NewCommandReconciler(client client.Client, recorder record.EventRecorder, ctx context.Context, command *papermciov1.PaperCommand) *CommandReconciler {
+	return &CommandReconciler{
+		client:   client,
+		recorder: recorder,
+		ctx:      ctx,
+		command:  command,
+
+		persistedStatus: command.Status.DeepCopy(),
+	}
+}
+
+// ReconcileDelay postpones the execution until the delay counted from the creation of the resource passed.
+func (r *CommandReconciler) ReconcileDelay() Result {
+	if r.command.Spec.DelaySeconds == nil {
+		return newSkippedResult()
+	}
+
+	runAt := r.command.CreationTimestamp.Add(time.Duration(*r.command.Spec.DelaySeconds) * time.Second)
+	if !time.Now().Before(runAt) {
+		return newSkippedResult()
+	}
+
+	if res := r.pending(fmt.Sprintf("Delayed until %s", runAt.UTC().Format(time.RFC3339))); res.Failed() {
+		return res
+	}
+	return newRequeueResult(time.Until(runAt))
+}
+
+// ReconcileExecution runs the commands once the server is available. Commands are run at most once: an execution
+// found running was interrupted, e.g. by a restart of the operator, and is reported as failed instead of repeated.
+func (r *CommandReconciler) ReconcileExecution() Result {
+	if r.command.Status.Phase == papermciov1.PaperCommandPhaseRunning {
+		return r.complete(papermciov1.PaperCommandPhaseFailed, "Execution was interrupted, commands are not run twice")
+	}
+
+	paper := papermciov1.Paper{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.command.Namespace, Name: r.command.Spec.PaperRef.Name}, &paper); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+		return r.retry(fmt.Sprintf("Paper %s not found", r.command.Spec.PaperRef.Name))
+	}
+
+	pod := corev1.Pod{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: paper.Namespace, Name: instancePodName(&paper)}, &pod); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+		return r.retry(fmt.Sprintf("Waiting for Paper %s to be available", paper.Name))
+	} else if pod.DeletionTimestamp != nil || !podReady(&pod) {
+		return r.retry(fmt.Sprintf("Waiting for Paper %s to be available", paper.Name))
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, rconTimeout)
+	defer cancel()
+
+	rc, err := dialRcon(ctx, r.client, &paper, &pod)
+	if err != nil {
+		return r.retry(fmt.Sprintf("RCON of Paper %s unavailable: %v", paper.Name, err))
+	}
+	defer rc.Close()
+
+	now := metav1.Now()
+	r.command.Status.Phase = papermciov1.PaperCommandPhaseRunning
+	r.command.Status.Message = fmt.Sprintf("Running commands against Paper %s", paper.Name)
+	r.command.Status.StartedTimestamp = &now
+	if res := r.updateStatus(); res.Failed() {
+		return res
+	}
+
+	for _, command := range r.command.Spec.Commands {
+		result := papermciov1.CommandResult{Command: command}
+
+		ctx, cancel := context.WithTimeout(r.ctx, rconTimeout)
+		output, err := rc.Command(ctx, command)
+		cancel()
+
+		result.Output = output
+		result.Succeeded = err == nil
+		if err != nil {
+			result.Error = err.Error()
+		}
+		r.command.Status.Results = append(r.command.Status.Results, result)
+
+		if err != nil {
+			return r.complete(papermciov1.PaperCommandPhaseFailed, fmt.Sprintf("Command %q failed: %v", command, err))
+		}
+	}
+
+	return r.complete(papermciov1.PaperCommandPhaseSucceeded, fmt.Sprintf("Ran %d commands against Paper %s", len(r.command.Spec.Commands), paper.Name))
+}
+
+// retry reports the execution as pending and checks again later.
+func (r *CommandReconciler) retry(message string) Result {
+	if res := r.pending(message); res.Failed() {
+		return res
+	}
+	return newRequeueResult(commandRetryInterval)
+}
+
+func (r *CommandReconciler) pending(message string) Result {
+	r.command.Status.Phase = papermciov1.PaperCommandPhasePending
+	r.command.Status.Message = message
+	return r.updateStatus()
+}
+
+// complete reports the execution as finished and records an event, leaving an audit trail of the commands.
+func (r *CommandReconciler) complete(phase papermciov1.PaperCommandPhase, message string) Result {
+	now := metav1.Now()
+	r.command.Status.Phase = phase
+	r.command.Status.Message = message
+	r.command.Status.CompletedTimestamp = &now
+	if res := r.updateStatus(); res.Failed() {
+		return res
+	}
+
+	if phase == papermciov1.PaperCommandPhaseSucceeded {
+		r.recorder.Event(r.command, corev1.EventTypeNormal, eventReasonCommandSucceeded, message)
+	} else {
+		r.recorder.Event(r.command, corev1.EventTypeWarning, eventReasonCommandFailed, message)
+	}
+
+	return newUpdatedResult()
+}
+
+// updateStatus writes the status if it differs from the one last read or written. The result is skipped if nothing
+// changed.
+func (r *CommandReconciler) updateStatus() Result {
+	if equality.Semantic.DeepEqual(r.persistedStatus, &r.command.Status) {
+		return newSkippedResult()
+	}
+
+	if err := r.client.Status().Update(r.ctx, r.command); err != nil {
+		return newFailedResult(err)
+	}
+	r.persistedStatus = r.command.Status.DeepCopy()
+
+	return newUpdatedResult()
+}
+
+// podReady reports whether the Pod passes its readiness probe.
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
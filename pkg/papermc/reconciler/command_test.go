@@ -0,0 +1,118 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+// newCommandTest returns a reconciler of a PaperCommand running the given commands against a Paper resource, and the
+// Pod of its server, not ready yet.
+func newCommandTest(t *testing.T, status papermciov1.PaperCommandStatus, commands ...string) (*CommandReconciler, *record.FakeRecorder, *corev1.Pod) {
+	paper := &papermciov1.Paper{ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default"}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: instancePodName(paper), Namespace: paper.Namespace},
+		Status:     corev1.PodStatus{PodIP: "127.0.0.1"},
+	}
+	command := &papermciov1.PaperCommand{
+		ObjectMeta: metav1.ObjectMeta{Name: "command", Namespace: paper.Namespace},
+		Spec: papermciov1.PaperCommandSpec{
+			PaperRef: corev1.LocalObjectReference{Name: paper.Name},
+			Commands: commands,
+		},
+		Status: status,
+	}
+
+	c, _ := newFakeClient(t, paper, pod, rconSecret(paper), command)
+
+	read := &papermciov1.PaperCommand{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(command), read))
+	recorder := record.NewFakeRecorder(10)
+	return NewCommandReconciler(c, recorder, context.Background(), read), recorder, pod
+}
+
+func setPodReady(t *testing.T, r *CommandReconciler, pod *corev1.Pod) {
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	require.NoError(t, r.client.Status().Update(r.ctx, pod))
+}
+
+// getCommand returns the PaperCommand as persisted.
+func getCommand(t *testing.T, r *CommandReconciler) *papermciov1.PaperCommand {
+	persisted := &papermciov1.PaperCommand{}
+	require.NoError(t, r.client.Get(r.ctx, client.ObjectKeyFromObject(r.command), persisted))
+	return persisted
+}
+
+func TestReconcileExecution(t *testing.T) {
+	server := newFakeRconServer(t, map[string]string{"list": "There are 0 of a max of 20 players online: "})
+	r, recorder, pod := newCommandTest(t, papermciov1.PaperCommandStatus{}, "list", "whitelist add alice")
+
+	// the server is not available yet
+	res := r.ReconcileExecution()
+	require.NoError(t, res.GetError())
+	assert.Equal(t, commandRetryInterval, res.RequeueAfter())
+	assert.Equal(t, papermciov1.PaperCommandPhasePending, getCommand(t, r).Status.Phase)
+	assert.Empty(t, server.Commands())
+
+	setPodReady(t, r, pod)
+	res = r.ReconcileExecution()
+	require.NoError(t, res.GetError())
+	assert.True(t, res.Updated())
+	assert.Zero(t, res.RequeueAfter())
+	assert.Equal(t, []string{"list", "whitelist add alice"}, server.Commands())
+
+	persisted := getCommand(t, r)
+	assert.Equal(t, papermciov1.PaperCommandPhaseSucceeded, persisted.Status.Phase)
+	assert.NotNil(t, persisted.Status.StartedTimestamp)
+	assert.NotNil(t, persisted.Status.CompletedTimestamp)
+	assert.Equal(t, []papermciov1.CommandResult{
+		{Command: "list", Output: "There are 0 of a max of 20 players online: ", Succeeded: true},
+		{Command: "whitelist add alice", Succeeded: true},
+	}, persisted.Status.Results)
+	assert.Contains(t, <-recorder.Events, eventReasonCommandSucceeded)
+}
+
+func TestReconcileExecutionStopsAtFailingCommand(t *testing.T) {
+	server := newFakeRconServer(t, nil)
+	server.hangUpOn("reload")
+	r, recorder, pod := newCommandTest(t, papermciov1.PaperCommandStatus{}, "save-all", "reload", "whitelist add alice")
+	setPodReady(t, r, pod)
+
+	res := r.ReconcileExecution()
+	require.NoError(t, res.GetError())
+	assert.True(t, res.Updated())
+	assert.Equal(t, []string{"save-all", "reload"}, server.Commands())
+
+	persisted := getCommand(t, r)
+	assert.Equal(t, papermciov1.PaperCommandPhaseFailed, persisted.Status.Phase)
+	require.Len(t, persisted.Status.Results, 2)
+	assert.True(t, persisted.Status.Results[0].Succeeded)
+	assert.False(t, persisted.Status.Results[1].Succeeded)
+	assert.NotEmpty(t, persisted.Status.Results[1].Error)
+	assert.Contains(t, <-recorder.Events, eventReasonCommandFailed)
+}
+
+func TestReconcileExecutionInterrupted(t *testing.T) {
+	server := newFakeRconServer(t, nil)
+	r, recorder, pod := newCommandTest(t, papermciov1.PaperCommandStatus{Phase: papermciov1.PaperCommandPhaseRunning}, "whitelist add alice")
+	setPodReady(t, r, pod)
+
+	res := r.ReconcileExecution()
+	require.NoError(t, res.GetError())
+	assert.True(t, res.Updated())
+	// commands are not run twice
+	assert.Empty(t, server.Commands())
+
+	persisted := getCommand(t, r)
+	assert.Equal(t, papermciov1.PaperCommandPhaseFailed, persisted.Status.Phase)
+	assert.Empty(t, persisted.Status.Results)
+	assert.Contains(t, <-recorder.Events, eventReasonCommandFailed)
+}
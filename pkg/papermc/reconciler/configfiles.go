@@ -0,0 +1,217 @@
+package reconciler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+const (
+	// annotationConfigFilesHash records the content of the config files the Pod was started with, the server is
+	// restarted once it differs. Files with a reload command are excluded, they are synced while running.
+	annotationConfigFilesHash = "papermc.io/config-files-hash"
+
+	configFilesVolume    = "config-files"
+	configFilesMountPath = "/config-files"
+	configFilesManifest  = "manifest.json"
+)
+
+// configFile is an entry of the manifest of the config files Secret, read by the helper.
+type configFile struct {
+	Key           string `json:"key"`
+	Path          string `json:"path"`
+	ReloadCommand string `json:"reloadCommand,omitempty"`
+}
+
+// renderedConfigFiles summarizes the config files once rendered.
+type renderedConfigFiles struct {
+	// hash covers the content of files restarting the server
+	hash string
+	// reloadable is true if any file is synced while running
+	reloadable bool
+}
+
+// ReconcileConfigFiles renders the config files into a Secret, which is mounted by the helper copying them into the
+// data directory.
+func (r *Reconciler) ReconcileConfigFiles() Result {
+	if len(r.paper.Spec.ConfigFiles) == 0 {
+		r.configFiles = nil
+		return r.deleteConfigFilesSecret()
+	}
+
+	data := map[string][]byte{}
+	manifest := make([]configFile, 0, len(r.paper.Spec.ConfigFiles))
+	restartingContent := map[string]string{}
+	reloadable := false
+	for i, f := range r.paper.Spec.ConfigFiles {
+		content, err := r.configFileContent(f)
+		if err != nil {
+			return r.failDegraded(reasonConfigFileFailed, fmt.Sprintf("Rendering config file %s failed: %v", f.Path, err), err)
+		}
+
+		key := fmt.Sprintf("file-%d", i)
+		data[key] = content
+		manifest = append(manifest, configFile{Key: key, Path: f.Path, ReloadCommand: f.ReloadCommand})
+		if f.ReloadCommand == "" {
+			restartingContent[f.Path] = string(content)
+		} else {
+			reloadable = true
+		}
+	}
+
+	raw, err := json.Marshal(manifest)
+	if err != nil {
+		return newFailedResult(err)
+	}
+	data[configFilesManifest] = raw
+
+	r.configFiles = &renderedConfigFiles{
+		hash:       hashOf(restartingContent),
+		reloadable: reloadable,
+	}
+
+	return r.apply(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configFilesSecretName(r.paper.Name),
+			Namespace: r.paper.Namespace,
+			Labels:    labelsForPaperInstance(r.paper),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	})
+}
+
+// configFileContent reads the content of a config file from its source, rendering it if it is a template.
+func (r *Reconciler) configFileContent(f papermciov1.ConfigFileSpec) ([]byte, error) {
+	if !filepath.IsLocal(f.Path) {
+		return nil, fmt.Errorf("path must be within the data directory")
+	}
+
+	var content []byte
+	switch {
+	case f.ConfigMap != nil:
+		cm := corev1.ConfigMap{}
+		if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: f.ConfigMap.Name}, &cm); err != nil {
+			return nil, fmt.Errorf("failed to get ConfigMap %s: %w", f.ConfigMap.Name, err)
+		}
+		if value, ok := cm.Data[f.ConfigMap.Key]; ok {
+			content = []byte(value)
+		} else if value, ok := cm.BinaryData[f.ConfigMap.Key]; ok {
+			content = value
+		} else {
+			return nil, fmt.Errorf("key %s not found in ConfigMap %s", f.ConfigMap.Key, f.ConfigMap.Name)
+		}
+	case f.Secret != nil:
+		value, err := r.secretValue(f.Secret.Name, f.Secret.Key)
+		if err != nil {
+			return nil, err
+		}
+		content = []byte(value)
+	default:
+		return nil, fmt.Errorf("no source given")
+	}
+
+	if !f.Template {
+		return content, nil
+	}
+
+	tmpl, err := template.New(f.Path).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"secret": r.referencedSecretValue(f), "quote": quote}).
+		Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, nil); err != nil {
+		return nil, err
+	}
+	return rendered.Bytes(), nil
+}
+
+// quote renders a value as double-quoted string, escaped as in JSON. Such strings are valid in YAML and JSON files alike,
+// whatever characters the value contains.
+func quote(value string) (string, error) {
+	var quoted bytes.Buffer
+	encoder := json.NewEncoder(&quoted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSuffix(quoted.Bytes(), []byte("\n"))), nil
+}
+
+// referencedSecretValue returns the secret template function of a config file, reading the Secrets it references only.
+func (r *Reconciler) referencedSecretValue(f papermciov1.ConfigFileSpec) func(string, string) (string, error) {
+	return func(name string, key string) (string, error) {
+		for _, ref := range f.SecretRefs {
+			if ref.Name == name {
+				return r.secretValue(name, key)
+			}
+		}
+		return "", fmt.Errorf("template may not read Secret %s, it is not listed in secretRefs", name)
+	}
+}
+
+// secretValue reads a key of a Secret in the namespace of the Paper resource.
+func (r *Reconciler) secretValue(name string, key string) (string, error) {
+	secret := corev1.Secret{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: name}, &secret); err != nil {
+		return "", fmt.Errorf("failed to get Secret %s: %w", name, err)
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in Secret %s", key, name)
+	}
+	return string(value), nil
+}
+
+// deleteConfigFilesSecret removes the Secret once no config files are given anymore.
+func (r *Reconciler) deleteConfigFilesSecret() Result {
+	existingSecret := corev1.Secret{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: configFilesSecretName(r.paper.Name)}, &existingSecret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+		return newSkippedResult()
+	}
+
+	if err := r.client.Delete(r.ctx, &existingSecret); err != nil && !apierrors.IsNotFound(err) {
+		return newFailedResult(err)
+	}
+	return newUpdatedResult()
+}
+
+func (r *Reconciler) configFilesVolumeSource() corev1.Volume {
+	return corev1.Volume{
+		Name: configFilesVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  configFilesSecretName(r.paper.Name),
+				DefaultMode: pointer.Int32(0400),
+			},
+		},
+	}
+}
+
+// configFilesHash is the hash of the config files restarting the server, empty if there are none.
+func (r *Reconciler) configFilesHash() string {
+	if r.configFiles == nil {
+		return ""
+	}
+	return r.configFiles.hash
+}
+
+func configFilesSecretName(name string) string {
+	return fmt.Sprintf("%s-config-files", name)
+}
@@ -0,0 +1,69 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+func TestQuote(t *testing.T) {
+	for _, value := range []string{
+		"plain",
+		"",
+		`with "quotes" and \backslash`,
+		"key: value # comment",
+		"multi\nline\ttabbed",
+		"- <html> & {flow}",
+		"yes",
+		"0123",
+	} {
+		t.Run(value, func(t *testing.T) {
+			quoted, err := quote(value)
+			require.NoError(t, err)
+
+			var parsed map[string]interface{}
+			require.NoError(t, yaml.Unmarshal([]byte("password: "+quoted+"\n"), &parsed))
+			assert.Equal(t, value, parsed["password"])
+		})
+	}
+}
+
+func TestConfigFileContentSecretRefs(t *testing.T) {
+	paper := &papermciov1.Paper{ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default"}}
+	template := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "luckperms-config", Namespace: paper.Namespace},
+		Data:       map[string]string{"config.yml": `password: {{ secret "luckperms-db" "password" | quote }}`},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "luckperms-db", Namespace: paper.Namespace},
+		Data:       map[string][]byte{"password": []byte("s3cr3t")},
+	}
+	c, scheme := newFakeClient(t, paper, template, secret)
+	r := NewPaperReconciler(c, c, scheme, record.NewFakeRecorder(10), context.Background(), paper, Options{})
+
+	f := papermciov1.ConfigFileSpec{
+		Path: "plugins/LuckPerms/config.yml",
+		ConfigMap: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "luckperms-config"},
+			Key:                  "config.yml",
+		},
+		Template: true,
+	}
+
+	// Secrets not referenced by the config file are not read
+	_, err := r.configFileContent(f)
+	assert.ErrorContains(t, err, "not listed in secretRefs")
+
+	f.SecretRefs = []corev1.LocalObjectReference{{Name: "luckperms-db"}}
+	content, err := r.configFileContent(f)
+	require.NoError(t, err)
+	assert.Equal(t, `password: "s3cr3t"`, string(content))
+}
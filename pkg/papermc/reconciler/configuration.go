@@ -0,0 +1,147 @@
+package reconciler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+const (
+	configurationEula             = "eula.txt"
+	configurationServerProperties = "server.properties"
+
+	// settings merged onto the configuration files of Paper, Bukkit and Spigot by the helper
+	configurationPaperGlobal        = "paper-global.yml"
+	configurationPaperWorldDefaults = "paper-world-defaults.yml"
+	configurationBukkit             = "bukkit.yml"
+	configurationSpigot             = "spigot.yml"
+)
+
+// configurationData computes the content of the ConfigMap for the instance.
+func configurationData(p *papermciov1.Paper) map[string]string {
+	data := map[string]string{
+		configurationEula: fmt.Sprintf("eula=%t", p.Spec.Eula.Accepted),
+	}
+
+	if properties := serverProperties(p); len(properties) > 0 {
+		data[configurationServerProperties] = renderServerProperties(properties)
+	}
+
+	if pc := p.Spec.PaperConfig; pc != nil {
+		for key, settings := range map[string]*runtime.RawExtension{
+			configurationPaperGlobal:        pc.Global,
+			configurationPaperWorldDefaults: pc.WorldDefaults,
+			configurationBukkit:             pc.Bukkit,
+			configurationSpigot:             pc.Spigot,
+		} {
+			// JSON is valid YAML, kept as is to not lose precision of numbers
+			if settings != nil && len(settings.Raw) > 0 {
+				data[key] = string(settings.Raw)
+			}
+		}
+	}
+
+	return data
+}
+
+// hashOf computes a stable hash of the given data, e.g. to detect changes of configuration.
+func hashOf(data interface{}) string {
+	// json encodes maps with sorted keys
+	raw, _ := json.Marshal(data)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// serverProperties collects the properties managed by the operator.
+func serverProperties(p *papermciov1.Paper) map[string]string {
+	properties := map[string]string{}
+
+	if sp := p.Spec.ServerProperties; sp != nil {
+		for k, v := range sp.Additional {
+			properties[k] = v
+		}
+
+		if sp.Motd != nil {
+			properties["motd"] = *sp.Motd
+		}
+		if sp.Difficulty != "" {
+			properties["difficulty"] = string(sp.Difficulty)
+		}
+		if sp.Gamemode != "" {
+			properties["gamemode"] = string(sp.Gamemode)
+		}
+		if sp.MaxPlayers != nil {
+			properties["max-players"] = strconv.Itoa(int(*sp.MaxPlayers))
+		}
+		if sp.ViewDistance != nil {
+			properties["view-distance"] = strconv.Itoa(int(*sp.ViewDistance))
+		}
+		if sp.OnlineMode != nil {
+			properties["online-mode"] = strconv.FormatBool(*sp.OnlineMode)
+		}
+	}
+
+	if access := p.Spec.Access; access != nil && access.Whitelist != nil {
+		properties["white-list"] = strconv.FormatBool(access.Whitelist.Enabled)
+		properties["enforce-whitelist"] = strconv.FormatBool(access.Whitelist.Enforced)
+	}
+
+	return properties
+}
+
+// renderServerProperties renders the properties in the format of server.properties, sorted by key.
+func renderServerProperties(properties map[string]string) string {
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sb := strings.Builder{}
+	sb.WriteString("# managed by papermc-operator\n")
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("%s=%s\n", escapeProperty(k, true), escapeProperty(properties[k], false)))
+	}
+	return sb.String()
+}
+
+// escapeProperty escapes a key or value following the format of java.util.Properties.
+func escapeProperty(s string, key bool) string {
+	sb := strings.Builder{}
+	for i, c := range s {
+		switch {
+		case c == '\\':
+			sb.WriteString(`\\`)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\r':
+			sb.WriteString(`\r`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c == ' ' && (key || i == 0):
+			sb.WriteString(`\ `)
+		case key && (c == '=' || c == ':' || c == '#' || c == '!'):
+			sb.WriteRune('\\')
+			sb.WriteRune(c)
+		case c < 0x20 || c > 0x7e:
+			if r1, r2 := utf16.EncodeRune(c); r1 != unicode.ReplacementChar {
+				sb.WriteString(fmt.Sprintf(`\u%04X\u%04X`, r1, r2))
+			} else {
+				sb.WriteString(fmt.Sprintf(`\u%04X`, c))
+			}
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,44 @@
+package reconciler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeProperty(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		key  bool
+		want string
+	}{
+		{name: "plain value", s: "A Minecraft Server", want: "A Minecraft Server"},
+		{name: "leading spaces of value", s: "  indented", want: `\  indented`},
+		{name: "separators in value", s: "a=b:c #d !e", want: "a=b:c #d !e"},
+		{name: "separators in key", s: "a=b:c#d!e", key: true, want: `a\=b\:c\#d\!e`},
+		{name: "spaces in key", s: "a b", key: true, want: `a\ b`},
+		{name: "line breaks and tabs", s: "a\nb\r\tc", want: `a\nb\r\tc`},
+		{name: "backslash", s: `C:\worlds`, want: `C:\\worlds`},
+		{name: "control character", s: "a\x01b", want: `a\u0001b`},
+		{name: "non-ascii", s: "Grüße", want: `Gr\u00FC\u00DFe`},
+		{name: "non-bmp as surrogate pair", s: "go 😀", want: `go \uD83D\uDE00`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, escapeProperty(tt.s, tt.key))
+		})
+	}
+}
+
+func TestRenderServerProperties(t *testing.T) {
+	assert.Equal(t, `# managed by papermc-operator
+difficulty=hard
+max-players=20
+motd=\ Welcome\u00A7r
+`, renderServerProperties(map[string]string{
+		"motd":        " Welcome\u00a7r",
+		"max-players": "20",
+		"difficulty":  "hard",
+	}))
+}
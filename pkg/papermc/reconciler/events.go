@@ -0,0 +1,31 @@
+package reconciler
+
+// Reasons of the events recorded for a Paper resource. Alerting may match on them, so they must not change. Warnings
+// recorded along with the Degraded condition share its reason, e.g. ChecksumMismatch, DownloadFailed or PapermcApiError.
+const (
+	eventReasonEulaNotAccepted     = "EulaNotAccepted"
+	eventReasonBuildDiscovered     = "BuildDiscovered"
+	eventReasonDownloadStarted     = "DownloadStarted"
+	eventReasonDownloadFinished    = "DownloadFinished"
+	eventReasonVolumeExpanding     = "VolumeExpanding"
+	eventReasonInstanceStarting    = "InstanceStarting"
+	eventReasonInstanceFailed      = "InstanceFailed"
+	eventReasonUpgrading           = "Upgrading"
+	eventReasonRestarting          = "Restarting"
+	eventReasonOrphanDeleted       = "OrphanDeleted"
+	eventReasonDriftCorrected      = "DriftCorrected"
+	eventReasonAccessApplied       = "AccessApplied"
+	eventReasonPluginsResolved     = "PluginsResolved"
+	eventReasonBackupCompleted     = "BackupCompleted"
+	eventReasonBackupFailed        = "BackupFailed"
+	eventReasonBackupUnschedulable = "BackupUnschedulable"
+
+	// EventReasonReconcileFailed is recorded by the controller if any step of the reconciliation fails.
+	EventReasonReconcileFailed = "ReconcileFailed"
+)
+
+// Reasons of the events recorded for a PaperCommand resource.
+const (
+	eventReasonCommandSucceeded = "CommandSucceeded"
+	eventReasonCommandFailed    = "CommandFailed"
+)
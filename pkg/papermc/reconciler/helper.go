@@ -0,0 +1,133 @@
+package reconciler
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	helperVolume    = "helper"
+	helperMountPath = "/helper"
+	helperBinary    = "papermc-helper"
+)
+
+// helperInitContainer builds the init container copying the helper into the helper volume.
+func (r *Reconciler) helperInitContainer() corev1.Container {
+	return corev1.Container{
+		Name:    "install-helper",
+		Image:   r.options.HelperImage,
+		Command: []string{fmt.Sprintf("/%s", helperBinary), "install", helperMountPath},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      helperVolume,
+			MountPath: helperMountPath,
+		}},
+		SecurityContext: secureContainerSecurityContext(),
+	}
+
+}
+
+func helperVolumeSource() corev1.Volume {
+	return corev1.Volume{
+		Name: helperVolume,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+}
+
+// configureInitContainer builds the init container writing the configuration into the data directory, see the
+// configure command of the helper.
+func (r *Reconciler) configureInitContainer() corev1.Container {
+	container := corev1.Container{
+		Name:    "configure",
+		Image:   r.options.HelperImage,
+		Command: []string{fmt.Sprintf("/%s", helperBinary), "configure", "--config=/config", "--data=/app/data", fmt.Sprintf("--rcon-port=%d", rconPort)},
+		Env:     []corev1.EnvVar{r.rconPasswordEnvVar()},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "app-data",
+				MountPath: "/app/data",
+			},
+			{
+				Name:      "configuration",
+				MountPath: "/config",
+				ReadOnly:  true,
+			},
+		},
+		SecurityContext: secureContainerSecurityContext(),
+	}
+
+	if r.paper.Status.Plugins != nil {
+		// installs the plugins into the plugins directory of the server
+		container.Command = append(container.Command, fmt.Sprintf("--plugins=%s", pluginsMountPath))
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      pluginsVolume,
+			MountPath: pluginsMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	if r.configFiles != nil {
+		// copies the config files into the data directory
+		container.Command = append(container.Command, fmt.Sprintf("--files=%s", configFilesMountPath))
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      configFilesVolume,
+			MountPath: configFilesMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	return container
+}
+
+// syncFilesContainer builds the container syncing config files with a reload command while the server is running,
+// see the sync-files command of the helper.
+func (r *Reconciler) syncFilesContainer() corev1.Container {
+	return corev1.Container{
+		Name:  "sync-files",
+		Image: r.options.HelperImage,
+		Command: []string{
+			fmt.Sprintf("/%s", helperBinary), "sync-files",
+			fmt.Sprintf("--files=%s", configFilesMountPath), "--data=/app/data", fmt.Sprintf("--rcon-address=127.0.0.1:%d", rconPort),
+		},
+		Env: []corev1.EnvVar{r.rconPasswordEnvVar()},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "app-data",
+				MountPath: "/app/data",
+			},
+			{
+				Name:      configFilesVolume,
+				MountPath: configFilesMountPath,
+				ReadOnly:  true,
+			},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("16Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+		},
+		SecurityContext: secureContainerSecurityContext(),
+	}
+}
+
+// rconPasswordEnvVar passes the RCON password to the helper.
+func (r *Reconciler) rconPasswordEnvVar() corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: "RCON_PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: rconSecretName(r.paper.Name),
+				},
+				Key: rconSecretKeyPassword,
+			},
+		},
+	}
+}
@@ -0,0 +1,240 @@
+package reconciler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+// DefaultDownloaderImage is the image downloading artifacts and plugins if the operator is not configured otherwise.
+const DefaultDownloaderImage = "docker.io/busybox:latest"
+
+// DefaultServerImages are the images running the server by major version of Java, if the operator is not configured
+// otherwise. A version of Minecraft runs on the image of the lowest version of Java it supports.
+var DefaultServerImages = map[int32]string{
+	17: "gcr.io/distroless/java17-debian11:nonroot",
+	21: "gcr.io/distroless/java21-debian12:nonroot",
+}
+
+// javaVersions maps versions of Minecraft to the major version of Java they require at least, by the first version
+// requiring it, newest first.
+var javaVersions = []struct {
+	since string
+	java  int32
+}{
+	{since: "1.20.5", java: 21},
+	{since: "1.18", java: 17},
+	{since: "1.17", java: 16},
+	{since: "0", java: 8},
+}
+
+// javaVersionFor returns the major version of Java required by the given version of Minecraft.
+func javaVersionFor(version string) int32 {
+	for _, v := range javaVersions {
+		if compareVersions(version, v.since) >= 0 {
+			return v.java
+		}
+	}
+	return javaVersions[len(javaVersions)-1].java
+}
+
+// serverImageFor returns the image of the lowest version of Java at least the required one, and its version of Java.
+func serverImageFor(images map[int32]string, java int32) (string, int32, error) {
+	versions := make([]int32, 0, len(images))
+	for v := range images {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	for _, v := range versions {
+		if v >= java {
+			return images[v], v, nil
+		}
+	}
+	return "", 0, fmt.Errorf("no server image for Java %d or later configured", java)
+}
+
+// ReconcileRuntime selects the images for the desired version, they are reported in the status and used by the Pods
+// created afterwards.
+func (r *Reconciler) ReconcileRuntime() Result {
+	java := javaVersionFor(r.paper.Status.DesiredState.Version.Version)
+
+	runtime := &papermciov1.RuntimeStatus{
+		JavaVersion:     java,
+		DownloaderImage: DefaultDownloaderImage,
+	}
+
+	if r.options.DownloaderImage != "" {
+		runtime.DownloaderImage = r.options.DownloaderImage
+	}
+	if images := r.paper.Spec.Images; images != nil && images.Downloader != "" {
+		runtime.DownloaderImage = images.Downloader
+	}
+
+	if images := r.paper.Spec.Images; images != nil && images.Server != "" {
+		runtime.ServerImage = images.Server
+		if previous := r.paper.Status.Runtime; previous != nil && previous.ServerImage == images.Server {
+			// detected before, see ReconcileJavaCompatibility
+			runtime.ServerImageJavaVersion = previous.ServerImageJavaVersion
+		}
+	} else {
+		serverImages := r.options.ServerImages
+		if len(serverImages) == 0 {
+			serverImages = DefaultServerImages
+		}
+		image, imageJava, err := serverImageFor(serverImages, java)
+		if err != nil {
+			return r.failDegraded(reasonNoServerImage, fmt.Sprintf("Version %s requires Java %d: %v", r.paper.Status.DesiredState.Version.Version, java, err), err)
+		}
+		runtime.ServerImage = image
+		runtime.ServerImageJavaVersion = imageJava
+	}
+
+	r.paper.Status.Runtime = runtime
+	return r.updateStatus()
+}
+
+// ReconcileJavaCompatibility refuses to run the desired version on a server image providing an older version of Java
+// than it requires, instead of crashing the server in a loop. Only starting and replacing the server is held back, see
+// ReconcilePaperInstance, a server running another version keeps running and the other steps proceed. The version of
+// Java of an image given by the Paper resource is detected by running the image once, the desired version is held back
+// likewise while that fails.
+func (r *Reconciler) ReconcileJavaCompatibility() Result {
+	runtime := r.paper.Status.Runtime
+	if runtime.ServerImageJavaVersion == 0 {
+		return r.reconcileJavaCheck()
+	}
+	if runtime.ServerImageJavaVersion >= runtime.JavaVersion {
+		return newSkippedResult()
+	}
+	r.javaIncompatible = true
+
+	message := fmt.Sprintf("Version %s requires Java %d, but image %s provides Java %d, refusing to run it",
+		r.paper.Status.DesiredState.Version.Version, runtime.JavaVersion, runtime.ServerImage, runtime.ServerImageJavaVersion)
+	res := r.updateStatus(
+		condition(conditionTypeDegraded, metav1.ConditionTrue, reasonJavaIncompatible, message),
+		condition(conditionTypeProgressing, metav1.ConditionFalse, reasonJavaIncompatible, "Waiting for a server image providing the required version of Java"),
+	)
+	if res.Failed() {
+		return res
+	} else if res.Updated() {
+		r.recorder.Event(r.paper, corev1.EventTypeWarning, reasonJavaIncompatible, message)
+	}
+	return res
+}
+
+// reconcileJavaCheck runs the server image once to detect its version of Java, see the java-version command of the
+// helper. The result is recorded in the status.
+func (r *Reconciler) reconcileJavaCheck() Result {
+	name := fmt.Sprintf("%s-java-check", r.paper.Name)
+	image := r.paper.Status.Runtime.ServerImage
+
+	existingPod := corev1.Pod{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: name}, &existingPod); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+	} else if existingPod.DeletionTimestamp != nil {
+		// give it a moment
+		return newUpdatedResult()
+	} else if existingPod.Spec.Containers[0].Image != image {
+		// check of another image, start over
+		return r.deleteJavaCheck(&existingPod)
+	} else if existingPod.Status.Phase == corev1.PodSucceeded {
+		version, err := strconv.Atoi(terminationMessage(&existingPod))
+		if err != nil || version < 1 {
+			return newFailedResult(fmt.Errorf("unexpected java version %q reported by %s", terminationMessage(&existingPod), name))
+		}
+		r.paper.Status.Runtime.ServerImageJavaVersion = int32(version)
+		if res := r.updateStatus(); res.Failed() {
+			return res
+		}
+		return r.deleteJavaCheck(&existingPod)
+	} else if existingPod.Status.Phase == corev1.PodFailed {
+		// kept until the image changes or it is deleted to retry, the desired version is held back as if incompatible
+		r.javaIncompatible = true
+
+		message := fmt.Sprintf("Detecting the version of Java of image %s failed, delete Pod %s to retry: %s", image, name, terminationMessage(&existingPod))
+		res := r.updateStatus(condition(conditionTypeDegraded, metav1.ConditionTrue, reasonJavaCheckFailed, message))
+		if res.Failed() {
+			return res
+		} else if res.Updated() {
+			r.recorder.Event(r.paper, corev1.EventTypeWarning, reasonJavaCheckFailed, message)
+		}
+		return res
+	} else {
+		// give it a moment
+		return newUpdatedResult()
+	}
+
+	pod, err := r.withPodTemplate(r.javaCheckPod(name, image), false)
+	if err != nil {
+		return newFailedResult(err)
+	}
+
+	if err := ctrl.SetControllerReference(r.paper, pod, r.scheme); err != nil {
+		return newFailedResult(err)
+	}
+
+	if err := r.client.Create(r.ctx, pod); err != nil {
+		return newFailedResult(err)
+	}
+
+	return newUpdatedResult()
+}
+
+func (r *Reconciler) deleteJavaCheck(pod *corev1.Pod) Result {
+	if err := r.client.Delete(r.ctx, pod); err != nil && !(apierrors.IsNotFound(err) || apierrors.IsGone(err)) {
+		return newFailedResult(err)
+	}
+	return newUpdatedResult()
+}
+
+// javaCheckPod builds the Pod printing the version of Java of the given image.
+func (r *Reconciler) javaCheckPod(name string, image string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: r.paper.Namespace,
+			Labels:    labelsForPaperInstance(r.paper),
+		},
+		Spec: corev1.PodSpec{
+			AutomountServiceAccountToken: pointer.Bool(false),
+			InitContainers:               []corev1.Container{r.helperInitContainer()},
+			Containers: []corev1.Container{{
+				Name:    containerName,
+				Image:   image,
+				Command: []string{fmt.Sprintf("%s/%s", helperMountPath, helperBinary), "java-version"},
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      helperVolume,
+					MountPath: helperMountPath,
+					ReadOnly:  true,
+				}},
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				SecurityContext:          secureContainerSecurityContext(),
+			}},
+			RestartPolicy:   corev1.RestartPolicyNever,
+			SecurityContext: securePodSecurityContext(),
+			Volumes:         []corev1.Volume{helperVolumeSource()},
+		},
+	}
+}
+
+// imageForPaperDownloader returns the image selected by ReconcileRuntime.
+func (r *Reconciler) imageForPaperDownloader(p *papermciov1.Paper) string {
+	return p.Status.Runtime.DownloaderImage
+}
+
+// imageForPaperInstance returns the image selected by ReconcileRuntime.
+func (r *Reconciler) imageForPaperInstance(p *papermciov1.Paper) string {
+	return p.Status.Runtime.ServerImage
+}
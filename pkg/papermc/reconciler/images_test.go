@@ -0,0 +1,93 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+func TestJavaIncompatibleKeepsRunningServer(t *testing.T) {
+	paper := &papermciov1.Paper{
+		ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default"},
+		Spec:       papermciov1.PaperSpec{Version: "1.20.6", Eula: papermciov1.EulaSpec{Accepted: true}},
+		Status: papermciov1.PaperStatus{
+			DesiredState: &papermciov1.DesiredState{Version: papermciov1.Version{Version: "1.20.6", Build: 1}},
+			Runtime:      &papermciov1.RuntimeStatus{JavaVersion: 21, ServerImage: "eclipse-temurin:17-jre", ServerImageJavaVersion: 17},
+		},
+	}
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default", Labels: map[string]string{labelVersion: "1.20.4-1"}},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: containerName, Ready: true}},
+		},
+	}
+	c, scheme := newFakeClient(t, paper, running)
+
+	r := NewPaperReconciler(c, c, scheme, record.NewFakeRecorder(10), context.Background(), paper, Options{})
+
+	res := r.ReconcileJavaCompatibility()
+	require.NoError(t, res.GetError())
+	assert.True(t, res.Updated())
+	degraded := meta.FindStatusCondition(paper.Status.Conditions, conditionTypeDegraded)
+	require.NotNil(t, degraded)
+	assert.Equal(t, reasonJavaIncompatible, degraded.Reason)
+
+	// the chain proceeds once reported
+	assert.True(t, r.ReconcileJavaCompatibility().Skipped())
+
+	assert.True(t, r.ReconcilePaperInstance().Skipped())
+	pod := &corev1.Pod{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(running), pod))
+	assert.Nil(t, pod.DeletionTimestamp)
+
+	res = r.ReconcileStatus()
+	require.NoError(t, res.GetError())
+	assert.True(t, paper.Status.Instance.Ready)
+	assert.Nil(t, paper.Status.ActualState)
+	assert.Equal(t, reasonJavaIncompatible, meta.FindStatusCondition(paper.Status.Conditions, conditionTypeDegraded).Reason)
+
+	assert.True(t, r.ReconcileOrphanObjects().Skipped())
+}
+
+func TestJavaCheckFailedProceeds(t *testing.T) {
+	paper := &papermciov1.Paper{
+		ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default"},
+		Spec:       papermciov1.PaperSpec{Version: "1.20.6", Eula: papermciov1.EulaSpec{Accepted: true}},
+		Status: papermciov1.PaperStatus{
+			DesiredState: &papermciov1.DesiredState{Version: papermciov1.Version{Version: "1.20.6", Build: 1}},
+			Runtime:      &papermciov1.RuntimeStatus{JavaVersion: 21, ServerImage: "example.com/java:custom"},
+		},
+	}
+	check := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "paper-java-check", Namespace: "default"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: containerName, Image: "example.com/java:custom"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+	}
+	c, scheme := newFakeClient(t, paper, check)
+
+	r := NewPaperReconciler(c, c, scheme, record.NewFakeRecorder(10), context.Background(), paper, Options{})
+
+	res := r.ReconcileJavaCompatibility()
+	require.NoError(t, res.GetError())
+	assert.True(t, res.Updated())
+	assert.Equal(t, reasonJavaCheckFailed, meta.FindStatusCondition(paper.Status.Conditions, conditionTypeDegraded).Reason)
+
+	// the chain proceeds once reported, the desired version is held back
+	assert.True(t, r.ReconcileJavaCompatibility().Skipped())
+	assert.True(t, r.ReconcilePaperInstance().Skipped())
+
+	// the check is kept for inspection
+	pod := &corev1.Pod{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(check), pod))
+	assert.Nil(t, pod.DeletionTimestamp)
+}
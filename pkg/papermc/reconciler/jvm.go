@@ -0,0 +1,95 @@
+package reconciler
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+const (
+	defaultHeapPercentage = 75
+
+	// aikarLargeHeapThreshold is the heap size above which the large heap variant of Aikar's flags applies
+	aikarLargeHeapThreshold = 12 * 1024
+)
+
+var (
+	aikarFlags = []string{
+		"-XX:+UseG1GC",
+		"-XX:+ParallelRefProcEnabled",
+		"-XX:MaxGCPauseMillis=200",
+		"-XX:+UnlockExperimentalVMOptions",
+		"-XX:+DisableExplicitGC",
+		"-XX:+AlwaysPreTouch",
+		"-XX:G1HeapWastePercent=5",
+		"-XX:G1MixedGCCountTarget=4",
+		"-XX:G1MixedGCLiveThresholdPercent=90",
+		"-XX:G1RSetUpdatingPauseTimePercent=5",
+		"-XX:SurvivorRatio=32",
+		"-XX:+PerfDisableSharedMem",
+		"-XX:MaxTenuringThreshold=1",
+		"-Dusing.aikars.flags=https://mcflags.emc.gs",
+		"-Daikars.new.flags=true",
+	}
+	aikarFlagsRegularHeap = []string{
+		"-XX:G1NewSizePercent=30",
+		"-XX:G1MaxNewSizePercent=40",
+		"-XX:G1HeapRegionSize=8M",
+		"-XX:G1ReservePercent=20",
+		"-XX:InitiatingHeapOccupancyPercent=15",
+	}
+	aikarFlagsLargeHeap = []string{
+		"-XX:G1NewSizePercent=40",
+		"-XX:G1MaxNewSizePercent=50",
+		"-XX:G1HeapRegionSize=16M",
+		"-XX:G1ReservePercent=15",
+		"-XX:InitiatingHeapOccupancyPercent=20",
+	}
+)
+
+// javaArgs computes the arguments of the java command running the server.
+func javaArgs(p *papermciov1.Paper, jar string) []string {
+	jvm := p.Spec.Jvm
+	if jvm == nil {
+		jvm = &papermciov1.JvmSpec{}
+	}
+
+	var args []string
+
+	heap := heapSizeMegabytes(jvm, p.Spec.Resources)
+	if heap > 0 {
+		args = append(args, fmt.Sprintf("-Xms%dM", heap), fmt.Sprintf("-Xmx%dM", heap))
+	}
+
+	if jvm.Flags == papermciov1.JvmFlagsAikar {
+		args = append(args, aikarFlags...)
+		if heap > aikarLargeHeapThreshold {
+			args = append(args, aikarFlagsLargeHeap...)
+		} else {
+			args = append(args, aikarFlagsRegularHeap...)
+		}
+	}
+
+	args = append(args, jvm.ExtraJvmArgs...)
+	args = append(args, "-jar", jar)
+	args = append(args, jvm.ExtraServerArgs...)
+
+	return args
+}
+
+// heapSizeMegabytes derives the heap size from the memory limit, 0 if there is no limit.
+func heapSizeMegabytes(jvm *papermciov1.JvmSpec, resources corev1.ResourceRequirements) int64 {
+	limit, ok := resources.Limits[corev1.ResourceMemory]
+	if !ok || limit.IsZero() {
+		return 0
+	}
+
+	percentage := int64(defaultHeapPercentage)
+	if jvm.HeapPercentage != nil {
+		percentage = int64(*jvm.HeapPercentage)
+	}
+
+	return limit.Value() * percentage / 100 / (1024 * 1024)
+}
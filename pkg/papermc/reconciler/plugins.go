@@ -0,0 +1,424 @@
+package reconciler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+	"github.com/baichinger/papermc-operator/pkg/papermc/plugin"
+)
+
+const (
+	labelPlugins = "papermc.io/plugins"
+
+	pluginSourceUrl       = "Url"
+	pluginSourceHangar    = "Hangar"
+	pluginSourceModrinth  = "Modrinth"
+	pluginSourceConfigMap = "ConfigMap"
+
+	pluginsVolume          = "plugins"
+	pluginsMountPath       = "/app/plugins"
+	pluginSourcesMountPath = "/sources"
+	pluginSourceFile       = "plugin.jar"
+
+	// pluginsProvisionerScript downloads the plugins listed in PLUGINS, one per line given by name, URL or path,
+	// checksum algorithm and checksum. Plugins are moved into place only if their checksum matches, the failing plugin
+	// is reported by the termination message.
+	pluginsProvisionerScript = `printf '%s\n' "$PLUGINS" | while read -r name src algo sum; do
+[ -n "$name" ] || continue
+case "$src" in
+/*) cp "$src" "$name.jar.download" ;;
+*) wget -O "$name.jar.download" "$src" ;;
+esac || { echo "$name: download of $src failed" > /dev/termination-log; exit 1; }
+echo "$sum  $name.jar.download" | "${algo}sum" -c - || { rm -f "$name.jar.download"; echo "$name: checksum mismatch" > /dev/termination-log; exit 2; }
+mv "$name.jar.download" "$name.jar"
+done`
+)
+
+// ReconcilePlugins resolves the plugins to downloads. Plugins are resolved again once the spec changes, and
+// periodically if a repository is asked for its latest release.
+func (r *Reconciler) ReconcilePlugins() Result {
+	if len(r.paper.Spec.Plugins) == 0 {
+		if r.paper.Status.Plugins == nil {
+			return newSkippedResult()
+		}
+		r.paper.Status.Plugins = nil
+		return r.updateStatus()
+	}
+
+	selector, err := r.pluginsSelector()
+	if err != nil {
+		return r.failDegraded(reasonPluginResolutionFailed, fmt.Sprintf("Resolving plugins failed: %v", err), err)
+	}
+
+	now := metav1.Now()
+	if ps := r.paper.Status.Plugins; ps != nil && ps.Selector == selector &&
+		(pluginsPinned(r.paper) || ps.UpdatedTimestamp.Time.Add(desiredVersionUpdateInterval).After(now.Time)) {
+		return newSkippedResult()
+	}
+
+	items := make([]papermciov1.PluginStatus, 0, len(r.paper.Spec.Plugins))
+	for _, p := range r.paper.Spec.Plugins {
+		item, err := r.resolvePlugin(p)
+		if err != nil {
+			return r.failDegraded(reasonPluginResolutionFailed, fmt.Sprintf("Resolving plugin %s failed: %v", p.Name, err), err)
+		}
+		items = append(items, item)
+	}
+
+	hash := hashOf(items)[:10]
+	if ps := r.paper.Status.Plugins; ps != nil && ps.Hash == hash {
+		// nothing changed, keep readiness
+		items = ps.Items
+	} else {
+		r.recorder.Eventf(r.paper, corev1.EventTypeNormal, eventReasonPluginsResolved, "Resolved plugins %s", describePlugins(items))
+	}
+
+	r.paper.Status.Plugins = &papermciov1.PluginsStatus{
+		Hash:             hash,
+		UpdatedTimestamp: now,
+		Selector:         selector,
+		Items:            items,
+	}
+	return r.updateStatus()
+}
+
+// pluginsSelector identifies the inputs of resolving plugins: the spec, the version of the server and the content of
+// ConfigMaps holding plugins.
+func (r *Reconciler) pluginsSelector() (string, error) {
+	configMaps := map[string]string{}
+	for _, p := range r.paper.Spec.Plugins {
+		if p.ConfigMap == nil {
+			continue
+		}
+		content, err := r.pluginFromConfigMap(p.ConfigMap)
+		if err != nil {
+			return "", err
+		}
+		configMaps[p.Name] = sha256Of(content)
+	}
+
+	return hashOf(map[string]interface{}{
+		"plugins":     r.paper.Spec.Plugins,
+		"gameVersion": r.paper.Status.DesiredState.Version.Version,
+		"configMaps":  configMaps,
+	}), nil
+}
+
+// pluginsPinned reports whether no plugin follows the latest release of a repository.
+func pluginsPinned(p *papermciov1.Paper) bool {
+	for _, plugin := range p.Spec.Plugins {
+		if (plugin.Hangar != nil && plugin.Hangar.Version == "") || (plugin.Modrinth != nil && plugin.Modrinth.Version == "") {
+			return false
+		}
+	}
+	return true
+}
+
+// resolvePlugin resolves a plugin to a verifiable download.
+func (r *Reconciler) resolvePlugin(p papermciov1.PluginSpec) (papermciov1.PluginStatus, error) {
+	gameVersion := r.paper.Status.DesiredState.Version.Version
+
+	switch {
+	case p.Url != nil:
+		return papermciov1.PluginStatus{
+			Name:     p.Name,
+			Source:   pluginSourceUrl,
+			Url:      p.Url.Url,
+			Checksum: "sha256:" + strings.ToLower(p.Url.Sha256),
+		}, nil
+	case p.Hangar != nil:
+		return r.resolvePluginFromRepository(p.Name, pluginSourceHangar, r.hangarSource(), p.Hangar, gameVersion)
+	case p.Modrinth != nil:
+		return r.resolvePluginFromRepository(p.Name, pluginSourceModrinth, r.modrinthSource(), p.Modrinth, gameVersion)
+	case p.ConfigMap != nil:
+		content, err := r.pluginFromConfigMap(p.ConfigMap)
+		if err != nil {
+			return papermciov1.PluginStatus{}, err
+		}
+		return papermciov1.PluginStatus{
+			Name:     p.Name,
+			Source:   pluginSourceConfigMap,
+			Url:      fmt.Sprintf("configmap:%s/%s", p.ConfigMap.Name, p.ConfigMap.Key),
+			Checksum: "sha256:" + sha256Of(content),
+		}, nil
+	default:
+		return papermciov1.PluginStatus{}, fmt.Errorf("no source given")
+	}
+}
+
+func (r *Reconciler) resolvePluginFromRepository(name string, sourceName string, source plugin.Source, spec *papermciov1.RepositoryPluginSource, gameVersion string) (papermciov1.PluginStatus, error) {
+	artifact, err := source.Resolve(r.ctx, spec.Project, spec.Version, gameVersion)
+	if err != nil {
+		return papermciov1.PluginStatus{}, fmt.Errorf("%s project %s: %w", sourceName, spec.Project, err)
+	}
+
+	return papermciov1.PluginStatus{
+		Name:     name,
+		Source:   sourceName,
+		Version:  artifact.Version,
+		Url:      artifact.Url,
+		Checksum: fmt.Sprintf("%s:%s", artifact.Algorithm, strings.ToLower(artifact.Checksum)),
+	}, nil
+}
+
+func (r *Reconciler) hangarSource() plugin.Source {
+	if r.options.HangarSource != nil {
+		return r.options.HangarSource
+	}
+	return plugin.NewHangarSource()
+}
+
+func (r *Reconciler) modrinthSource() plugin.Source {
+	if r.options.ModrinthSource != nil {
+		return r.options.ModrinthSource
+	}
+	return plugin.NewModrinthSource()
+}
+
+// pluginFromConfigMap reads a plugin from binary data, or data, of a ConfigMap.
+func (r *Reconciler) pluginFromConfigMap(selector *corev1.ConfigMapKeySelector) ([]byte, error) {
+	cm := corev1.ConfigMap{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: selector.Name}, &cm); err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s: %w", selector.Name, err)
+	}
+	if content, ok := cm.BinaryData[selector.Key]; ok {
+		return content, nil
+	}
+	if content, ok := cm.Data[selector.Key]; ok {
+		return []byte(content), nil
+	}
+	return nil, fmt.Errorf("key %s not found in ConfigMap %s", selector.Key, selector.Name)
+}
+
+func (r *Reconciler) ReconcilePersistentVolumeClaimForPlugins() Result {
+	if r.paper.Status.Plugins == nil {
+		return newSkippedResult()
+	}
+
+	var volume *papermciov1.VolumeSpec
+	if r.paper.Spec.Storage != nil {
+		volume = r.paper.Spec.Storage.Plugins
+	}
+
+	return r.reconcilePersistentVolumeClaim(pluginsObjectName(r.paper), labelsForPlugins(r.paper), volume, *resource.NewScaledQuantity(200, resource.Mega))
+}
+
+// ReconcileProvisionerForPlugins downloads and verifies the resolved plugins into the volume of the set of plugins.
+func (r *Reconciler) ReconcileProvisionerForPlugins() Result {
+	ps := r.paper.Status.Plugins
+	if ps == nil || pluginsReady(ps) {
+		return newSkippedResult()
+	}
+
+	name := pluginsObjectName(r.paper)
+
+	existingPod := corev1.Pod{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: name}, &existingPod); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+	} else if existingPod.DeletionTimestamp != nil {
+		// give it a moment
+		return newUpdatedResult()
+	} else if existingPod.Status.Phase == corev1.PodFailed {
+		message := terminationMessage(&existingPod)
+		if failed, reason, found := strings.Cut(message, ": "); found {
+			for i := range ps.Items {
+				if ps.Items[i].Name == failed {
+					ps.Items[i].Message = reason
+				}
+			}
+		}
+		if res := r.setDegraded(reasonPluginDownloadFailed, fmt.Sprintf("Download of plugins failed, retrying: %s", message)); res.Failed() {
+			return res
+		}
+		// delete and try again
+		err := r.client.Delete(r.ctx, &existingPod)
+		if err != nil && !(apierrors.IsNotFound(err) || apierrors.IsGone(err)) {
+			return newFailedResult(err)
+		}
+		return newUpdatedResult()
+	} else if existingPod.Status.Phase == corev1.PodSucceeded {
+		for i := range ps.Items {
+			ps.Items[i].Ready = true
+			ps.Items[i].Message = ""
+		}
+		if res := r.updateStatus(); res.Failed() {
+			return res
+		}
+		r.recorder.Eventf(r.paper, corev1.EventTypeNormal, eventReasonDownloadFinished, "Downloaded and verified plugins %s", describePlugins(ps.Items))
+		return newUpdatedResult()
+	} else {
+		// nothing to do, provisioner Pod exists
+		return newUpdatedResult()
+	}
+
+	pod, err := r.withPodTemplate(r.pluginsProvisionerPod(name), false)
+	if err != nil {
+		return newFailedResult(err)
+	}
+
+	if err := ctrl.SetControllerReference(r.paper, pod, r.scheme); err != nil {
+		return newFailedResult(err)
+	}
+
+	if err := r.client.Create(r.ctx, pod); err != nil {
+		return newFailedResult(err)
+	}
+
+	r.recorder.Eventf(r.paper, corev1.EventTypeNormal, eventReasonDownloadStarted, "Downloading plugins %s", describePlugins(ps.Items))
+
+	return newUpdatedResult()
+}
+
+// pluginsProvisionerPod builds the Pod downloading the resolved plugins. Plugins from ConfigMaps are mounted and
+// copied.
+func (r *Reconciler) pluginsProvisionerPod(name string) *corev1.Pod {
+	volumeMounts := []corev1.VolumeMount{{
+		Name:      "data",
+		MountPath: "/data",
+	}}
+	volumes := []corev1.Volume{{
+		Name: "data",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: name,
+			},
+		},
+	}}
+
+	var manifest strings.Builder
+	for i, item := range r.paper.Status.Plugins.Items {
+		src := item.Url
+		if item.Source == pluginSourceConfigMap {
+			spec := r.pluginSpec(item.Name)
+			volume := fmt.Sprintf("source-%d", i)
+			volumes = append(volumes, corev1.Volume{
+				Name: volume,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: spec.ConfigMap.LocalObjectReference,
+						Items:                []corev1.KeyToPath{{Key: spec.ConfigMap.Key, Path: pluginSourceFile}},
+						DefaultMode:          pointer.Int32(0444),
+					},
+				},
+			})
+			mountPath := path.Join(pluginSourcesMountPath, item.Name)
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      volume,
+				MountPath: mountPath,
+				ReadOnly:  true,
+			})
+			src = path.Join(mountPath, pluginSourceFile)
+		}
+		algorithm, checksum, _ := strings.Cut(item.Checksum, ":")
+		manifest.WriteString(fmt.Sprintf("%s %s %s %s\n", item.Name, src, algorithm, checksum))
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: r.paper.Namespace,
+			Labels:    labelsForPlugins(r.paper),
+		},
+		Spec: corev1.PodSpec{
+			AutomountServiceAccountToken: pointer.Bool(false),
+			Containers: []corev1.Container{{
+				Name:       containerName,
+				Image:      r.imageForPaperDownloader(r.paper),
+				Command:    []string{"sh", "-c", pluginsProvisionerScript},
+				WorkingDir: "/data",
+				Env: []corev1.EnvVar{{
+					Name:  "PLUGINS",
+					Value: manifest.String(),
+				}},
+				VolumeMounts:    volumeMounts,
+				SecurityContext: secureContainerSecurityContext(),
+			}},
+			// ServiceAccountName: p.Name,
+			RestartPolicy:   corev1.RestartPolicyNever,
+			SecurityContext: securePodSecurityContext(),
+			Volumes:         volumes,
+		},
+	}
+}
+
+func (r *Reconciler) pluginSpec(name string) *papermciov1.PluginSpec {
+	for i := range r.paper.Spec.Plugins {
+		if r.paper.Spec.Plugins[i].Name == name {
+			return &r.paper.Spec.Plugins[i]
+		}
+	}
+	return nil
+}
+
+// pluginsVolumeSource builds the volume of the set of plugins, mounted by the init container installing them.
+func (r *Reconciler) pluginsVolumeSource() corev1.Volume {
+	return corev1.Volume{
+		Name: pluginsVolume,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pluginsObjectName(r.paper),
+				ReadOnly:  true,
+			},
+		},
+	}
+}
+
+func pluginsReady(ps *papermciov1.PluginsStatus) bool {
+	for _, item := range ps.Items {
+		if !item.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+func describePlugins(items []papermciov1.PluginStatus) string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Version != "" {
+			names = append(names, fmt.Sprintf("%s %s", item.Name, item.Version))
+		} else {
+			names = append(names, item.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// terminationMessage returns the message the container left on termination.
+func terminationMessage(pod *corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			return strings.TrimSpace(status.State.Terminated.Message)
+		}
+	}
+	return ""
+}
+
+func sha256Of(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func pluginsObjectName(p *papermciov1.Paper) string {
+	return fmt.Sprintf("%s-plugins-%s", p.Name, p.Status.Plugins.Hash)
+}
+
+func labelsForPlugins(p *papermciov1.Paper) map[string]string {
+	labels := labelsForPaperInstance(p)
+	labels[labelPlugins] = p.Status.Plugins.Hash
+	return labels
+}
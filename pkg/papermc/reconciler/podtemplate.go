@@ -0,0 +1,112 @@
+package reconciler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// withPodTemplate strategically merges the pod template of the Paper resource onto the given Pod. Env and volume mounts
+// are merged onto the server container, or the only container of other Pods. Only the Pod of the server gets the
+// containers, sidecars would keep the other Pods from completing. Labels and annotations of the operator take
+// precedence.
+func (r *Reconciler) withPodTemplate(pod *corev1.Pod, server bool) (*corev1.Pod, error) {
+	pt := r.paper.Spec.PodTemplate
+	if pt == nil {
+		return pod, nil
+	}
+
+	// only fields given are patched, empty fields of typed structs would clear the ones of the Pod
+	metadata := map[string]interface{}{}
+	if len(pt.Labels) > 0 {
+		metadata["labels"] = pt.Labels
+	}
+	if len(pt.Annotations) > 0 {
+		metadata["annotations"] = pt.Annotations
+	}
+
+	spec := map[string]interface{}{}
+	if len(pt.NodeSelector) > 0 {
+		spec["nodeSelector"] = pt.NodeSelector
+	}
+	if len(pt.Tolerations) > 0 {
+		spec["tolerations"] = pt.Tolerations
+	}
+	if pt.Affinity != nil {
+		spec["affinity"] = pt.Affinity
+	}
+	if len(pt.TopologySpreadConstraints) > 0 {
+		spec["topologySpreadConstraints"] = pt.TopologySpreadConstraints
+	}
+	if pt.PriorityClassName != "" {
+		spec["priorityClassName"] = pt.PriorityClassName
+	}
+	if pt.ServiceAccountName != "" {
+		spec["serviceAccountName"] = pt.ServiceAccountName
+	}
+	if len(pt.ImagePullSecrets) > 0 {
+		spec["imagePullSecrets"] = pt.ImagePullSecrets
+	}
+
+	if len(pt.Volumes) > 0 {
+		spec["volumes"] = pt.Volumes
+	}
+
+	var containers []interface{}
+	target := containerName
+	if server {
+		for _, c := range pt.Containers {
+			containers = append(containers, c)
+		}
+	} else {
+		target = pod.Spec.Containers[0].Name
+	}
+	if len(pt.Env) > 0 || len(pt.VolumeMounts) > 0 {
+		container := map[string]interface{}{"name": target}
+		if len(pt.Env) > 0 {
+			container["env"] = pt.Env
+		}
+		if len(pt.VolumeMounts) > 0 {
+			container["volumeMounts"] = pt.VolumeMounts
+		}
+		containers = append(containers, container)
+	}
+	if len(containers) > 0 {
+		spec["containers"] = containers
+	}
+
+	original, err := json.Marshal(pod)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": metadata, "spec": spec})
+	if err != nil {
+		return nil, err
+	}
+	merged, err := strategicpatch.StrategicMergePatch(original, patch, corev1.Pod{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge pod template: %w", err)
+	}
+
+	result := &corev1.Pod{}
+	if err := json.Unmarshal(merged, result); err != nil {
+		return nil, err
+	}
+	// the server container stays the default container
+	for i, c := range result.Spec.Containers {
+		if c.Name == containerName && i > 0 {
+			result.Spec.Containers = append([]corev1.Container{c}, append(result.Spec.Containers[:i:i], result.Spec.Containers[i+1:]...)...)
+			break
+		}
+	}
+	for k, v := range pod.Labels {
+		result.Labels[k] = v
+	}
+	for k, v := range pod.Annotations {
+		result.Annotations[k] = v
+	}
+
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package reconciler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+func newPodTemplatePaper() *papermciov1.Paper {
+	return &papermciov1.Paper{
+		ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default"},
+		Spec: papermciov1.PaperSpec{
+			PodTemplate: &papermciov1.PodTemplateSpec{
+				Labels:       map[string]string{"team": "minecraft", labelComponent: "overridden"},
+				Env:          []corev1.EnvVar{{Name: "HTTPS_PROXY", Value: "http://proxy:3128"}},
+				Volumes:      []corev1.Volume{{Name: "ca", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}}}}},
+				VolumeMounts: []corev1.VolumeMount{{Name: "ca", MountPath: "/etc/ssl/custom"}},
+				Containers:   []corev1.Container{{Name: "exporter", Image: "exporter:latest"}},
+			},
+		},
+	}
+}
+
+func TestWithPodTemplate(t *testing.T) {
+	tests := []struct {
+		name       string
+		server     bool
+		container  string
+		containers []string
+	}{
+		{
+			name:       "server",
+			server:     true,
+			container:  containerName,
+			containers: []string{containerName, "exporter"},
+		},
+		{
+			name:       "provisioner",
+			container:  "provisioner",
+			containers: []string{"provisioner"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{labelComponent: "operator"}, Annotations: map[string]string{}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: tt.container, Image: "image"}},
+					Volumes:    []corev1.Volume{{Name: "data"}},
+				},
+			}
+
+			result, err := (&Reconciler{paper: newPodTemplatePaper()}).withPodTemplate(pod, tt.server)
+			require.NoError(t, err)
+
+			var names []string
+			for _, c := range result.Spec.Containers {
+				names = append(names, c.Name)
+			}
+			assert.Equal(t, tt.containers, names)
+			assert.Equal(t, []corev1.EnvVar{{Name: "HTTPS_PROXY", Value: "http://proxy:3128"}}, result.Spec.Containers[0].Env)
+			assert.Equal(t, []corev1.VolumeMount{{Name: "ca", MountPath: "/etc/ssl/custom"}}, result.Spec.Containers[0].VolumeMounts)
+			assert.Len(t, result.Spec.Volumes, 2)
+			assert.Equal(t, "minecraft", result.Labels["team"])
+			assert.Equal(t, "operator", result.Labels[labelComponent])
+		})
+	}
+}
+
+func TestSpecHash(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: containerName, Image: "image"}}}}
+
+	paper := &papermciov1.Paper{}
+	assert.Equal(t, hashOf(pod.Spec), (&Reconciler{paper: paper}).specHash(pod))
+
+	paper.Spec.PodTemplate = &papermciov1.PodTemplateSpec{NodeSelector: map[string]string{"pool": "games"}}
+	assert.Equal(t, hashOf(pod.Spec), (&Reconciler{paper: paper}).specHash(pod))
+
+	paper.Spec.PodTemplate.Annotations = map[string]string{"prometheus.io/scrape": "true"}
+	scraped := (&Reconciler{paper: paper}).specHash(pod)
+	assert.NotEqual(t, hashOf(pod.Spec), scraped)
+
+	paper.Spec.PodTemplate.Annotations["prometheus.io/scrape"] = "false"
+	assert.NotEqual(t, scraped, (&Reconciler{paper: paper}).specHash(pod))
+}
@@ -0,0 +1,88 @@
+package reconciler
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+// probeTypeOf returns the effective probe type, applying the default if none is given.
+func probeTypeOf(p *papermciov1.Paper) papermciov1.ProbeType {
+	if p.Spec.Probes != nil && p.Spec.Probes.Type != "" {
+		return p.Spec.Probes.Type
+	}
+	return papermciov1.ProbeTypeTCP
+}
+
+// probes builds the startup, readiness and liveness probes of the server container.
+func probes(p *papermciov1.Paper) (startup *corev1.Probe, readiness *corev1.Probe, liveness *corev1.Probe) {
+	spec := p.Spec.Probes
+	if spec == nil {
+		spec = &papermciov1.ProbesSpec{}
+	}
+
+	startup = &corev1.Probe{
+		InitialDelaySeconds: 15,
+		PeriodSeconds:       5,
+		FailureThreshold:    60,
+	}
+	readiness = &corev1.Probe{
+		PeriodSeconds:    3,
+		FailureThreshold: 1,
+	}
+	liveness = &corev1.Probe{
+		PeriodSeconds: 5,
+	}
+
+	for probe, timings := range map[*corev1.Probe]*papermciov1.ProbeTimings{startup: spec.Startup, readiness: spec.Readiness, liveness: spec.Liveness} {
+		applyProbeTimings(probe, timings)
+		probe.ProbeHandler = probeHandler(probeTypeOf(p), probe.TimeoutSeconds)
+	}
+
+	return startup, readiness, liveness
+}
+
+func applyProbeTimings(probe *corev1.Probe, timings *papermciov1.ProbeTimings) {
+	if timings == nil {
+		return
+	}
+	if timings.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *timings.InitialDelaySeconds
+	}
+	if timings.PeriodSeconds != nil {
+		probe.PeriodSeconds = *timings.PeriodSeconds
+	}
+	if timings.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *timings.TimeoutSeconds
+	}
+	if timings.FailureThreshold != nil {
+		probe.FailureThreshold = *timings.FailureThreshold
+	}
+}
+
+func probeHandler(probeType papermciov1.ProbeType, timeoutSeconds int32) corev1.ProbeHandler {
+	if probeType == papermciov1.ProbeTypeStatus {
+		if timeoutSeconds == 0 {
+			// default of Kubernetes
+			timeoutSeconds = 1
+		}
+		return corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{
+					fmt.Sprintf("%s/%s", helperMountPath, helperBinary), "probe",
+					fmt.Sprintf("--address=127.0.0.1:%d", serverPort),
+					fmt.Sprintf("--timeout=%ds", timeoutSeconds),
+				},
+			},
+		}
+	}
+
+	return corev1.ProbeHandler{
+		TCPSocket: &corev1.TCPSocketAction{
+			Port: intstr.FromInt(serverPort),
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package reconciler
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+	"github.com/baichinger/papermc-operator/pkg/papermc/rcon"
+)
+
+const (
+	rconPort              = 25575
+	rconSecretKeyPassword = "password"
+	rconPasswordLength    = 32
+	rconTimeout           = 10 * time.Second
+
+	passwordAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// ReconcileRconSecret creates the Secret holding the RCON password of the server. The password is generated once and
+// kept as is afterwards.
+func (r *Reconciler) ReconcileRconSecret() Result {
+	existingSecret := corev1.Secret{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: rconSecretName(r.paper.Name)}, &existingSecret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+	} else {
+		// nothing to do, password exists
+		return newSkippedResult()
+	}
+
+	password, err := generatePassword(rconPasswordLength)
+	if err != nil {
+		return newFailedResult(err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rconSecretName(r.paper.Name),
+			Namespace: r.paper.Namespace,
+			Labels:    labelsForPaperInstance(r.paper),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			rconSecretKeyPassword: []byte(password),
+		},
+	}
+
+	if err := ctrl.SetControllerReference(r.paper, secret, r.scheme); err != nil {
+		return newFailedResult(err)
+	}
+
+	if err := r.client.Create(r.ctx, secret); err != nil {
+		return newFailedResult(err)
+	}
+
+	return newUpdatedResult()
+}
+
+// rconClient connects to the RCON port of the server running in the given Pod.
+func (r *Reconciler) rconClient(ctx context.Context, pod *corev1.Pod) (*rcon.Client, error) {
+	return dialRcon(ctx, r.client, r.paper, pod)
+}
+
+// dialRcon connects to the RCON port of the server of the Paper resource running in the given Pod.
+func dialRcon(ctx context.Context, c client.Client, paper *papermciov1.Paper, pod *corev1.Pod) (*rcon.Client, error) {
+	if pod.Status.PodIP == "" {
+		return nil, fmt.Errorf("pod %s has no IP", pod.Name)
+	}
+
+	secret := corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: paper.Namespace, Name: rconSecretName(paper.Name)}, &secret); err != nil {
+		return nil, err
+	}
+
+	return rcon.Dial(ctx, rconAddress(pod), string(secret.Data[rconSecretKeyPassword]))
+}
+
+// rconAddress is the address of the RCON port of the server running in the given Pod, tests replace it to connect to
+// a fake server.
+var rconAddress = func(pod *corev1.Pod) string {
+	return net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(rconPort))
+}
+
+// ReconcileRconService exposes RCON of the instance within the cluster.
+func (r *Reconciler) ReconcileRconService() Result {
+	return r.apply(r.rconService())
+}
+
+func rconSecretName(name string) string {
+	return fmt.Sprintf("%s-rcon", name)
+}
+
+func rconServiceName(name string) string {
+	return fmt.Sprintf("%s-rcon", name)
+}
+
+// generatePassword generates a random alphanumeric password, safe to be written to properties files unescaped.
+func generatePassword(length int) (string, error) {
+	password := make([]byte, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
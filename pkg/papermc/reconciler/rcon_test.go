@@ -0,0 +1,120 @@
+package reconciler
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+const fakeRconPassword = "secret"
+
+// fakeRconServer records the commands run via RCON and answers them with the given responses, empty by default. The
+// address of Pods is replaced by its address for the duration of the test.
+type fakeRconServer struct {
+	responses map[string]string
+
+	mu       sync.Mutex
+	commands []string
+	hangUp   string
+}
+
+func newFakeRconServer(t *testing.T, responses map[string]string) *fakeRconServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	address := rconAddress
+	rconAddress = func(*corev1.Pod) string { return listener.Addr().String() }
+	t.Cleanup(func() { rconAddress = address })
+
+	s := &fakeRconServer{responses: responses}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+// rconSecret is the RCON Secret of the Paper resource holding the password of the fake server.
+func rconSecret(paper *papermciov1.Paper) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: paper.Namespace, Name: rconSecretName(paper.Name)},
+		Data:       map[string][]byte{rconSecretKeyPassword: []byte(fakeRconPassword)},
+	}
+}
+
+func (s *fakeRconServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for {
+		var length int32
+		if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+			return
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return
+		}
+		id := binary.LittleEndian.Uint32(payload[0:4])
+		packetType := binary.LittleEndian.Uint32(payload[4:8])
+		body := string(payload[8 : length-2])
+
+		switch packetType {
+		case 3: // login
+			if body != fakeRconPassword {
+				id = 0xffffffff
+			}
+			s.write(conn, id, 2, "")
+		case 2: // command
+			s.mu.Lock()
+			s.commands = append(s.commands, body)
+			hangUp := s.hangUp == body
+			s.mu.Unlock()
+			if hangUp {
+				return
+			}
+			s.write(conn, id, 0, s.responses[body])
+		default:
+			s.write(conn, id, 0, "Unknown request")
+		}
+	}
+}
+
+func (s *fakeRconServer) write(conn net.Conn, id uint32, packetType uint32, body string) {
+	packet := binary.LittleEndian.AppendUint32(nil, uint32(len(body)+10))
+	packet = binary.LittleEndian.AppendUint32(packet, id)
+	packet = binary.LittleEndian.AppendUint32(packet, packetType)
+	packet = append(packet, body...)
+	packet = append(packet, 0, 0)
+	_, _ = conn.Write(packet)
+}
+
+// hangUpOn closes the connection once the given command is received, instead of answering it.
+func (s *fakeRconServer) hangUpOn(command string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hangUp = command
+}
+
+// Commands returns the commands run so far.
+func (s *fakeRconServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
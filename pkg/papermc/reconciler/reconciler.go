@@ -2,6 +2,7 @@ package reconciler
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -13,7 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -21,245 +22,500 @@ import (
 
 	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
 	papermc "github.com/baichinger/papermc-operator/pkg/papermc/client"
+	"github.com/baichinger/papermc-operator/pkg/papermc/plugin"
+	"github.com/baichinger/papermc-operator/pkg/papermc/profile"
 )
 
 const (
-	imageDownloader = "docker.io/busybox:latest"
-	imageServer     = "gcr.io/distroless/java17-debian11:nonroot"
+	labelName      = "app.kubernetes.io/name"
+	labelInstance  = "app.kubernetes.io/instance"
+	labelVersion   = "app.kubernetes.io/version"
+	labelComponent = "app.kubernetes.io/component"
 
-	labelName     = "app.kubernetes.io/name"
-	labelInstance = "app.kubernetes.io/instance"
-	labelVersion  = "app.kubernetes.io/version"
+	componentServer = "server"
 
-	objectName = "PaperMC"
+	annotationConfigurationHash = "papermc.io/configuration-hash"
+	annotationSpecHash          = "papermc.io/spec-hash"
+	annotationDownloadRecorded  = "papermc.io/download-recorded"
 
-	conditionTypeAvailable = "Available"
-	conditionTypeDegraded  = "Degraded"
+	objectName = "PaperMC"
 
 	runAsUserId = 1000
 
 	serverPort = 25565
 
 	desiredVersionUpdateInterval = 2 * time.Hour
+
+	exitCodeChecksumMismatch = 2
+
+	// provisionerScript downloads the artifact and moves it into place only if its checksum matches. The auth header of
+	// the Paper resource is sent along, if any.
+	provisionerScript = `wget ${PAPERMC_API_AUTH_HEADER:+--header "$PAPERMC_API_AUTH_HEADER"} -O paper.jar.download "$PAPER_URL" || exit 1
+echo "$PAPER_SHA256  paper.jar.download" | sha256sum -c - || { rm -f paper.jar.download; exit 2; }
+mv paper.jar.download paper.jar`
+
+	// verifierScript checks the checksum of a previously downloaded artifact
+	verifierScript = `echo "$PAPER_SHA256  paper.jar" | sha256sum -c - || exit 2`
 )
 
+// Options holds the manager wide settings for reconciling Paper resources.
+type Options struct {
+	// PapermcApi is the manager wide PaperMC API, a Paper resource may override it.
+	PapermcApi PapermcApi
+
+	// HelperImage is the image providing the helper binary, usually the image of the operator.
+	HelperImage string
+
+	// DownloaderImage and ServerImages, by major version of Java, default to DefaultDownloaderImage and
+	// DefaultServerImages. A Paper resource may override them.
+	DownloaderImage string
+	ServerImages    map[int32]string
+
+	// ProfileResolver resolves players of servers in online mode, defaults to the Mojang API.
+	ProfileResolver profile.Resolver
+
+	// HangarSource and ModrinthSource resolve plugins hosted by the repositories, default to their public APIs.
+	HangarSource   plugin.Source
+	ModrinthSource plugin.Source
+}
+
 type Reconciler struct {
-	client client.Client
-	scheme *runtime.Scheme
-	ctx    context.Context
-	paper  *papermciov1.Paper
+	client   client.Client
+	reader   client.Reader
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	ctx      context.Context
+	paper    *papermciov1.Paper
+	options  Options
+
+	// persistedStatus is the status last read or written, to detect changes
+	persistedStatus *papermciov1.PaperStatus
+	// access is the resolved access spec, once the configuration is reconciled
+	access *accessState
+	// configFiles are the rendered config files, once reconciled
+	configFiles *renderedConfigFiles
+	// javaIncompatible holds back starting the desired version, once the server image is found to provide an older
+	// version of Java than it requires, or its version of Java cannot be detected
+	javaIncompatible bool
 }
 
-func NewPaperReconciler(client client.Client, scheme *runtime.Scheme, ctx context.Context, paper *papermciov1.Paper) *Reconciler {
+// NewPaperReconciler creates a reconciler for the given Paper resource. The reader is expected to bypass the cache.
+func NewPaperReconciler(client client.Client, reader client.Reader, scheme *runtime.Scheme, recorder record.EventRecorder, ctx context.Context, paper *papermciov1.Paper, options Options) *Reconciler {
 	return &Reconciler{
-		client: client,
-		scheme: scheme,
-		ctx:    ctx,
-		paper:  paper,
+		client:   client,
+		reader:   reader,
+		scheme:   scheme,
+		recorder: recorder,
+		ctx:      ctx,
+		paper:    paper,
+		options:  options,
+
+		persistedStatus: paper.Status.DeepCopy(),
 	}
 }
 
 func (r *Reconciler) InitializeConditions() Result {
 	if r.paper.Status.Conditions == nil || len(r.paper.Status.Conditions) == 0 {
-		meta.SetStatusCondition(&r.paper.Status.Conditions, metav1.Condition{
-			Type:    conditionTypeAvailable,
-			Status:  metav1.ConditionUnknown,
-			Reason:  "Reconciling",
-			Message: "Starting reconciliation",
-		})
-		now := metav1.Now()
-		r.paper.Status.UpdatedTimestamp = &now
+		return r.updateStatus(
+			condition(conditionTypeAvailable, metav1.ConditionUnknown, reasonReconciling, "Starting reconciliation"),
+			condition(conditionTypeProgressing, metav1.ConditionUnknown, reasonReconciling, "Starting reconciliation"),
+			condition(conditionTypeDegraded, metav1.ConditionUnknown, reasonReconciling, "Starting reconciliation"),
+			condition(conditionTypeArtifactReady, metav1.ConditionUnknown, reasonReconciling, "Starting reconciliation"),
+			condition(conditionTypeUpgrading, metav1.ConditionUnknown, reasonReconciling, "Starting reconciliation"),
+		)
+	}
 
-		if err := r.client.Status().Update(r.ctx, r.paper); err != nil {
-			return newFailedResult(err)
-		}
+	return newSkippedResult()
+}
 
+func (r *Reconciler) ReconcileEula() Result {
+	if !r.paper.Spec.Eula.Accepted {
+		message := "Minecraft EULA (https://aka.ms/MinecraftEULA) must be accepted by setting spec.eula.accepted, refusing to start the server"
+		res := r.updateStatus(
+			condition(conditionTypeEula, metav1.ConditionFalse, reasonNotAccepted, message),
+			condition(conditionTypeProgressing, metav1.ConditionFalse, reasonNotAccepted, "Waiting for acceptance of the Minecraft EULA"),
+		)
+		if res.Failed() {
+			return res
+		} else if res.Updated() {
+			r.recorder.Event(r.paper, corev1.EventTypeWarning, eventReasonEulaNotAccepted, message)
+		}
+		// do not proceed, a server still running from before is left alone
 		return newUpdatedResult()
 	}
 
-	return newSkippedResult()
+	return r.updateStatus(condition(conditionTypeEula, metav1.ConditionTrue, reasonAccepted, "Minecraft EULA accepted by spec.eula.accepted"))
 }
 
 func (r *Reconciler) ReconcileDesiredVersion() Result {
 	now := metav1.Now()
-	if r.paper.Status.DesiredState != nil && r.paper.Status.DesiredState.Version.Version == r.paper.Spec.Version &&
-		r.paper.Status.DesiredState.UpdatedTimestamp.Time.Add(desiredVersionUpdateInterval).After(now.Time) {
+	selector := selectorForSpec(&r.paper.Spec)
+	if ds := r.paper.Status.DesiredState; ds != nil && ds.Selector == selector && ds.Sha256 != "" &&
+		(r.paper.Spec.GetUpdatePolicy() == papermciov1.UpdatePolicyPinned || ds.UpdatedTimestamp.Time.Add(desiredVersionUpdateInterval).After(now.Time)) {
 		return newSkippedResult()
 	}
 
-	pmcClient := papermc.NewPapermcClient(r.ctx)
+	pmcClient, err := r.papermcClient()
+	if err != nil {
+		return r.apiError(err)
+	}
 
-	if build, err := pmcClient.GetBuildForVersion(r.paper.Spec.Version); err != nil {
-		return newFailedResult(err)
-	} else if r.paper.Status.DesiredState == nil || r.paper.Status.DesiredState.Version.Version != r.paper.Spec.Version || r.paper.Status.DesiredState.Version.Build != build {
-		url, err := pmcClient.GetUrlForVersionBuildDownload(r.paper.Spec.Version, build)
+	if sel, err := selectBuild(pmcClient, &r.paper.Spec); err != nil {
+		return r.apiError(err)
+	} else if r.paper.Status.DesiredState == nil || r.paper.Status.DesiredState.Version.Version != sel.version || r.paper.Status.DesiredState.Version.Build != sel.build ||
+		r.paper.Status.DesiredState.Sha256 == "" {
+		url, err := pmcClient.GetUrlForVersionBuildDownload(sel.version, sel.build)
 		if err != nil {
-			return newFailedResult(err)
+			return r.apiError(err)
+		}
+
+		sha256, err := pmcClient.GetSha256ForVersionBuildDownload(sel.version, sel.build)
+		if err != nil {
+			return r.apiError(err)
 		}
 
 		r.paper.Status.DesiredState = &papermciov1.DesiredState{
 			Version: papermciov1.Version{
-				Version: r.paper.Spec.Version,
-				Build:   build,
+				Version: sel.version,
+				Build:   sel.build,
 			},
-			Url: url,
+			Url:      url,
+			Sha256:   sha256,
+			Reason:   sel.reason,
+			Selector: selector,
 		}
 
-		meta.SetStatusCondition(&r.paper.Status.Conditions, metav1.Condition{
-			Type:    conditionTypeAvailable,
-			Status:  metav1.ConditionFalse,
-			Reason:  "Reconciling",
-			Message: "Version, build, and url available",
-		})
+		if r.paper.Status.ActualState == nil || r.paper.Status.ActualState.Version != r.paper.Status.DesiredState.Version {
+			message := fmt.Sprintf("Selected version %s build %d", sel.version, sel.build)
+			meta.SetStatusCondition(&r.paper.Status.Conditions, condition(conditionTypeProgressing, metav1.ConditionTrue, reasonVersionSelected, message))
+			meta.SetStatusCondition(&r.paper.Status.Conditions, condition(conditionTypeArtifactReady, metav1.ConditionFalse, reasonPending, message))
+		}
+
+		r.recorder.Eventf(r.paper, corev1.EventTypeNormal, eventReasonBuildDiscovered, "Selected version %s build %d: %s", sel.version, sel.build, sel.reason)
+	} else {
+		r.paper.Status.DesiredState.Reason = sel.reason
+		r.paper.Status.DesiredState.Selector = selector
 	}
 
 	r.paper.Status.DesiredState.UpdatedTimestamp = now
-	r.paper.Status.UpdatedTimestamp = &now
 
-	if err := r.client.Status().Update(r.ctx, r.paper); err != nil {
-		return newFailedResult(err)
+	if res := r.updateStatus(); res.Failed() {
+		return res
 	}
 
 	return newUpdatedResult()
 }
 
-func (r *Reconciler) ReconcileStatus() Result {
-	if r.paper.Status.ActualState != nil && r.paper.Status.ActualState.Version == r.paper.Status.DesiredState.Version {
-		return newSkippedResult()
+// apiError records a failed request to the PaperMC API.
+func (r *Reconciler) apiError(err error) Result {
+	return r.failDegraded(reasonApiError, fmt.Sprintf("PaperMC API request failed: %v", err), err)
+}
+
+// papermcClient creates a client for the PaperMC API, applying the overrides of the Paper resource on top of the
+// manager wide API. The TLS trust and auth header of the manager wide API are dropped once the Paper resource points to
+// another API.
+func (r *Reconciler) papermcClient() (papermc.Client, error) {
+	opts := []papermc.Option{papermc.WithBaseUrl(r.options.PapermcApi.url())}
+
+	if r.managerApiAccess() {
+		if ca := r.options.PapermcApi.CaBundle; len(ca) > 0 {
+			pool, err := papermc.NewCertPool(ca)
+			if err != nil {
+				return nil, fmt.Errorf("invalid manager wide CA bundle: %w", err)
+			}
+			opts = append(opts, papermc.WithRootCAs(pool))
+		}
+		if header := r.options.PapermcApi.AuthHeader; header != "" {
+			name, value, err := papermc.ParseHeader(header)
+			if err != nil {
+				return nil, fmt.Errorf("invalid manager wide auth header: %w", err)
+			}
+			opts = append(opts, papermc.WithHeader(name, value))
+		}
 	}
 
-	r.paper.Status.ActualState = &papermciov1.ActualState{
-		Version: r.paper.Status.DesiredState.Version,
+	api := r.paper.Spec.Api
+	if api == nil {
+		return papermc.NewPapermcClient(r.ctx, opts...), nil
 	}
 
-	meta.SetStatusCondition(&r.paper.Status.Conditions, metav1.Condition{
-		Type:    conditionTypeAvailable,
-		Status:  metav1.ConditionTrue,
-		Reason:  "Reconciling",
-		Message: "Done",
-	})
+	if api.Url != "" {
+		opts = append(opts, papermc.WithBaseUrl(api.Url))
+	}
 
-	now := metav1.Now()
-	r.paper.Status.UpdatedTimestamp = &now
+	if api.CaBundle != nil {
+		cfg := &corev1.ConfigMap{}
+		if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: api.CaBundle.Name}, cfg); err != nil {
+			return nil, fmt.Errorf("failed to get CA bundle: %w", err)
+		}
+		pool, err := papermc.NewCertPool([]byte(cfg.Data[api.CaBundle.Key]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CA bundle in %s/%s: %w", api.CaBundle.Name, api.CaBundle.Key, err)
+		}
+		opts = append(opts, papermc.WithRootCAs(pool))
+	}
 
-	if err := r.client.Status().Update(r.ctx, r.paper); err != nil {
-		return newFailedResult(err)
+	if api.AuthHeader != nil {
+		secret := &corev1.Secret{}
+		if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: api.AuthHeader.Name}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get auth header: %w", err)
+		}
+		name, value, err := papermc.ParseHeader(string(secret.Data[api.AuthHeader.Key]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth header in %s/%s: %w", api.AuthHeader.Name, api.AuthHeader.Key, err)
+		}
+		opts = append(opts, papermc.WithHeader(name, value))
 	}
 
-	return newUpdatedResult()
+	return papermc.NewPapermcClient(r.ctx, opts...), nil
 }
 
-func (r *Reconciler) ReconcilePersistentVolumeClaimForDesiredVersion() Result {
-	name := buildObjectNameForVersion(r.paper.Name, r.paper.Status.DesiredState.Version)
+// ReconcileStatus reports the state of the running instance. It expects the instance to run the desired version,
+// unless it is held back by ReconcileJavaCompatibility.
+func (r *Reconciler) ReconcileStatus() Result {
+	pod := corev1.Pod{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: instancePodName(r.paper)}, &pod); err != nil {
+		if r.javaIncompatible && apierrors.IsNotFound(err) {
+			r.paper.Status.Instance = nil
+			r.paper.Status.Server = nil
+			return r.updateStatus(condition(conditionTypeAvailable, metav1.ConditionFalse, reasonJavaIncompatible, "Server is not running"))
+		}
+		return newFailedResult(err)
+	}
 
-	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: name}, &corev1.PersistentVolumeClaim{}); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return newFailedResult(err)
+	if r.javaIncompatible {
+		// the actual state and the conditions of the desired version are left as reported by the other steps
+		r.paper.Status.Instance = instanceStatus(&pod)
+		if !r.paper.Status.Instance.Ready {
+			r.paper.Status.Server = nil
+			return r.updateStatus(condition(conditionTypeAvailable, metav1.ConditionFalse, reasonNotReady, "Server is not ready"))
 		}
-	} else {
-		// nothing to do, PVC exists
-		return newSkippedResult()
+		return r.updateStatus(condition(conditionTypeAvailable, metav1.ConditionTrue, reasonRunning, fmt.Sprintf("Version %s is running", pod.Labels[labelVersion])))
 	}
 
-	pvc := &corev1.PersistentVolumeClaim{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: r.paper.Namespace,
-			Labels:    labelsForDesiredVersion(r.paper),
-		},
-		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-			Resources: corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: *resource.NewScaledQuantity(50, resource.Mega),
-				},
-			},
-		},
+	r.paper.Status.ActualState = &papermciov1.ActualState{
+		Version: r.paper.Status.DesiredState.Version,
+	}
+	r.paper.Status.Instance = instanceStatus(&pod)
+	if !r.paper.Status.Instance.Ready {
+		// refreshed by ReconcileServerStatus otherwise
+		r.paper.Status.Server = nil
 	}
 
-	err := ctrl.SetControllerReference(r.paper, pvc, r.scheme)
-	if err != nil {
-		return newFailedResult(err)
+	version := r.paper.Status.DesiredState.Version
+	conditions := []metav1.Condition{
+		condition(conditionTypeArtifactReady, metav1.ConditionTrue, reasonDownloaded, fmt.Sprintf("Version %s build %d downloaded and verified", version.Version, version.Build)),
+		condition(conditionTypeUpgrading, metav1.ConditionFalse, reasonUpToDate, fmt.Sprintf("Running version %s build %d", version.Version, version.Build)),
+	}
+	if r.paper.Status.Instance.Ready {
+		conditions = append(conditions,
+			condition(conditionTypeAvailable, metav1.ConditionTrue, reasonRunning, fmt.Sprintf("Version %s build %d is running", version.Version, version.Build)),
+			condition(conditionTypeProgressing, metav1.ConditionFalse, reasonReconciled, "Desired state reached"),
+		)
+	} else {
+		conditions = append(conditions,
+			condition(conditionTypeAvailable, metav1.ConditionFalse, reasonNotReady, "Server is not ready"),
+			condition(conditionTypeProgressing, metav1.ConditionTrue, reasonStarting, "Waiting for the server to become ready"),
+		)
+	}
+	if crashLooping(&pod) {
+		conditions = append(conditions, condition(conditionTypeDegraded, metav1.ConditionTrue, reasonCrashLooping,
+			fmt.Sprintf("Server keeps failing, restarted %d times, last terminated with %s", r.paper.Status.Instance.RestartCount, r.paper.Status.Instance.LastTerminationReason)))
+	} else {
+		conditions = append(conditions, condition(conditionTypeDegraded, metav1.ConditionFalse, reasonAsExpected, "Operating as expected"))
 	}
 
-	if err := r.client.Create(r.ctx, pvc); err != nil {
-		return newFailedResult(err)
+	return r.updateStatus(conditions...)
+}
+
+func (r *Reconciler) ReconcilePersistentVolumeClaimForDesiredVersion() Result {
+	name := buildObjectNameForVersion(r.paper.Name, r.paper.Status.DesiredState.Version)
+
+	var volume *papermciov1.VolumeSpec
+	if r.paper.Spec.Storage != nil {
+		volume = r.paper.Spec.Storage.Artifacts
 	}
 
-	return newUpdatedResult()
+	return r.reconcilePersistentVolumeClaim(name, labelsForDesiredVersion(r.paper), volume, *resource.NewScaledQuantity(50, resource.Mega))
 }
 
 func (r *Reconciler) ReconcilePersistentVolumeClaimForPaperInstance() Result {
-	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: r.paper.Name}, &corev1.PersistentVolumeClaim{}); err != nil {
+	var volume *papermciov1.VolumeSpec
+	if r.paper.Spec.Storage != nil {
+		volume = r.paper.Spec.Storage.World
+	}
+
+	size := *resource.NewScaledQuantity(1, resource.Giga)
+	if r.paper.Spec.GetWorkload() == papermciov1.WorkloadStatefulSet {
+		// claimed by the StatefulSet, only expanded once it exists
+		existingPvc := corev1.PersistentVolumeClaim{}
+		if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: instanceClaimName(r.paper)}, &existingPvc); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return newFailedResult(err)
+			}
+			return newSkippedResult()
+		}
+		if volume != nil && volume.Size != nil {
+			size = *volume.Size
+		}
+		return r.updatePersistentVolumeClaim(&existingPvc, labelsForPaperInstance(r.paper), size)
+	}
+
+	return r.reconcilePersistentVolumeClaim(r.paper.Name, labelsForPaperInstance(r.paper), volume, size)
+}
+
+func (r *Reconciler) ReconcileProvisionerForDesiredVersion() Result {
+	if r.paper.Status.DesiredState == nil {
+		return newFailedResult(fmt.Errorf("desired state undefined"))
+	}
+
+	name := buildObjectNameForVersion(r.paper.Name, r.paper.Status.DesiredState.Version)
+
+	if r.paper.Status.ActualState != nil && r.paper.Status.DesiredState.Version == r.paper.Status.ActualState.Version {
+		// nothing to do, provisioner already did its job
+		return newSkippedResult()
+	}
+
+	existingPod := corev1.Pod{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: name}, &existingPod); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return newFailedResult(err)
 		}
-	} else {
-		// nothing to do, PVC exists
+	} else if existingPod.DeletionTimestamp != nil {
+		// provisioner is being deleted, it is recreated once gone
+		return newUpdatedResult()
+	} else if existingPod.Status.Phase == corev1.PodFailed {
+		if terminatedExitCode(&existingPod) == exitCodeChecksumMismatch {
+			if res := r.setDegraded(reasonChecksumMismatch, fmt.Sprintf("Checksum of downloaded artifact %s does not match %s", r.paper.Status.DesiredState.Url, r.paper.Status.DesiredState.Sha256)); res.Failed() {
+				return res
+			}
+		} else {
+			if res := r.setDegraded(reasonDownloadFailed, fmt.Sprintf("Download of %s failed, retrying", r.paper.Status.DesiredState.Url)); res.Failed() {
+				return res
+			}
+		}
+		// delete and try again
+		err := r.client.Delete(r.ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: r.paper.Namespace, Name: name}})
+		if err != nil && !(apierrors.IsNotFound(err) || apierrors.IsGone(err)) {
+			return newFailedResult(err)
+		}
+		return newUpdatedResult()
+	} else if existingPod.Status.Phase == corev1.PodSucceeded {
+		if _, ok := existingPod.Annotations[annotationDownloadRecorded]; !ok {
+			return r.downloadFinished(&existingPod)
+		}
+		// move to next step, provisioner finished
 		return newSkippedResult()
+	} else {
+		// nothing to do, provisioner Pod exists
+		return newUpdatedResult()
 	}
 
-	pvc := &corev1.PersistentVolumeClaim{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      r.paper.Name,
-			Namespace: r.paper.Namespace,
-			Labels:    labelsForPaperInstance(r.paper),
-		},
-		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-			Resources: corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: *resource.NewScaledQuantity(1, resource.Giga),
-				},
-			},
-		},
+	pod, err := r.withPodTemplate(r.withApiAccess(r.artifactPod(name, name, provisionerScript, false)), false)
+	if err != nil {
+		return newFailedResult(err)
 	}
 
-	err := ctrl.SetControllerReference(r.paper, pvc, r.scheme)
+	err = ctrl.SetControllerReference(r.paper, pod, r.scheme)
 	if err != nil {
 		return newFailedResult(err)
 	}
 
-	if err := r.client.Create(r.ctx, pvc); err != nil {
+	if err := r.client.Create(r.ctx, pod); err != nil {
 		return newFailedResult(err)
 	}
 
+	message := fmt.Sprintf("Downloading version %s build %d from %s", r.paper.Status.DesiredState.Version.Version, r.paper.Status.DesiredState.Version.Build, r.paper.Status.DesiredState.Url)
+	r.recorder.Event(r.paper, corev1.EventTypeNormal, eventReasonDownloadStarted, message)
+
+	if res := r.updateStatus(
+		condition(conditionTypeArtifactReady, metav1.ConditionFalse, reasonDownloading, message),
+		condition(conditionTypeProgressing, metav1.ConditionTrue, reasonDownloading, message),
+	); res.Failed() {
+		return res
+	}
+
 	return newUpdatedResult()
 }
 
-func (r *Reconciler) ReconcileProvisionerForDesiredVersion() Result {
-	if r.paper.Status.DesiredState == nil {
-		return newFailedResult(fmt.Errorf("desired state undefined"))
+// downloadFinished records the successful download once, remembering it by an annotation on the provisioner Pod.
+func (r *Reconciler) downloadFinished(pod *corev1.Pod) Result {
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[annotationDownloadRecorded] = "true"
+	if err := r.client.Patch(r.ctx, pod, patch); err != nil {
+		return newFailedResult(err)
 	}
 
-	name := buildObjectNameForVersion(r.paper.Name, r.paper.Status.DesiredState.Version)
+	message := fmt.Sprintf("Downloaded and verified version %s build %d", r.paper.Status.DesiredState.Version.Version, r.paper.Status.DesiredState.Version.Build)
+	r.recorder.Event(r.paper, corev1.EventTypeNormal, eventReasonDownloadFinished, message)
 
-	if r.paper.Status.ActualState != nil && r.paper.Status.DesiredState.Version == r.paper.Status.ActualState.Version {
-		// nothing to do, provisioner already did its job
-		return newSkippedResult()
+	if res := r.updateStatus(condition(conditionTypeArtifactReady, metav1.ConditionTrue, reasonDownloaded, message)); res.Failed() {
+		return res
 	}
 
+	return newUpdatedResult()
+}
+
+// reconcileVerifierForDesiredVersion re-verifies the checksum of the artifact on the PVC for the desired version right
+// before an instance is moved onto it. The result is skipped once the verification succeeded.
+func (r *Reconciler) reconcileVerifierForDesiredVersion() Result {
+	artifactName := buildObjectNameForVersion(r.paper.Name, r.paper.Status.DesiredState.Version)
+	name := fmt.Sprintf("%s-verify", artifactName)
+
 	existingPod := corev1.Pod{}
 	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: name}, &existingPod); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return newFailedResult(err)
 		}
 	} else if existingPod.Status.Phase == corev1.PodFailed {
+		if terminatedExitCode(&existingPod) == exitCodeChecksumMismatch {
+			if res := r.setDegraded(reasonChecksumMismatch, fmt.Sprintf("Checksum of artifact %s does not match %s, downloading again", artifactName, r.paper.Status.DesiredState.Sha256)); res.Failed() {
+				return res
+			}
+			// remove provisioner to download the artifact again
+			err := r.client.Delete(r.ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: r.paper.Namespace, Name: artifactName}})
+			if err != nil && !(apierrors.IsNotFound(err) || apierrors.IsGone(err)) {
+				return newFailedResult(err)
+			}
+		}
 		// delete and try again
-		err := r.client.Delete(r.ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: r.paper.Namespace, Name: name}})
+		err := r.client.Delete(r.ctx, &existingPod)
 		if err != nil && !(apierrors.IsNotFound(err) || apierrors.IsGone(err)) {
 			return newFailedResult(err)
 		}
 		return newUpdatedResult()
 	} else if existingPod.Status.Phase == corev1.PodSucceeded {
-		// move to next step, provisioner finished
+		// artifact verified
 		return newSkippedResult()
 	} else {
-		// nothing to do, provisioner Pod exists
+		// give it a moment
 		return newUpdatedResult()
 	}
 
-	pod := &corev1.Pod{
+	pod, err := r.withPodTemplate(r.artifactPod(name, artifactName, verifierScript, true), false)
+	if err != nil {
+		return newFailedResult(err)
+	}
+
+	err = ctrl.SetControllerReference(r.paper, pod, r.scheme)
+	if err != nil {
+		return newFailedResult(err)
+	}
+
+	if err := r.client.Create(r.ctx, pod); err != nil {
+		return newFailedResult(err)
+	}
+
+	return newUpdatedResult()
+}
+
+// artifactPod builds a Pod running the given script against the PVC of the desired version. URL and checksum of the
+// artifact are passed in via environment.
+func (r *Reconciler) artifactPod(name string, claimName string, script string, readOnly bool) *corev1.Pod {
+	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: r.paper.Namespace,
@@ -268,13 +524,24 @@ func (r *Reconciler) ReconcileProvisionerForDesiredVersion() Result {
 		Spec: corev1.PodSpec{
 			AutomountServiceAccountToken: pointer.Bool(false),
 			Containers: []corev1.Container{{
-				Name:       "paper",
+				Name:       containerName,
 				Image:      r.imageForPaperDownloader(r.paper),
-				Command:    []string{"wget", "-O", "paper.jar", r.paper.Status.DesiredState.Url},
+				Command:    []string{"sh", "-c", script},
 				WorkingDir: "/data",
+				Env: []corev1.EnvVar{
+					{
+						Name:  "PAPER_URL",
+						Value: r.paper.Status.DesiredState.Url,
+					},
+					{
+						Name:  "PAPER_SHA256",
+						Value: r.paper.Status.DesiredState.Sha256,
+					},
+				},
 				VolumeMounts: []corev1.VolumeMount{{
 					Name:      "data",
 					MountPath: "/data",
+					ReadOnly:  readOnly,
 				}},
 				SecurityContext: secureContainerSecurityContext(),
 			}},
@@ -285,145 +552,247 @@ func (r *Reconciler) ReconcileProvisionerForDesiredVersion() Result {
 				Name: "data",
 				VolumeSource: corev1.VolumeSource{
 					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-						ClaimName: name,
+						ClaimName: claimName,
+						ReadOnly:  readOnly,
 					},
 				},
 			}},
 		},
 	}
-
-	err := ctrl.SetControllerReference(r.paper, pod, r.scheme)
-	if err != nil {
-		return newFailedResult(err)
-	}
-
-	if err := r.client.Create(r.ctx, pod); err != nil {
-		return newFailedResult(err)
-	}
-
-	return newUpdatedResult()
 }
 
 func (r *Reconciler) ReconcileConfigurationForPaperInstance() Result {
-	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: r.paper.Name}, &corev1.ConfigMap{}); err != nil {
-		if !apierrors.IsNotFound(err) {
+	// instance is restarted once the configuration hash differs, access is applied by commands instead
+	data := configurationData(r.paper)
+
+	access, err := r.resolveAccess()
+	if err != nil {
+		return r.failDegraded(reasonProfileResolutionFailed, fmt.Sprintf("Resolving players failed: %v", err), err)
+	}
+	if access != nil {
+		files, err := accessData(r.paper, access)
+		if err != nil {
 			return newFailedResult(err)
 		}
-	} else {
-		// nothing to do, configuration exists
-		return newSkippedResult()
+		for k, v := range files {
+			data[k] = v
+		}
 	}
+	r.access = access
 
-	cfg := &corev1.ConfigMap{
+	return r.apply(&corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.paper.Name,
 			Namespace: r.paper.Namespace,
 			Labels:    labelsForPaperInstance(r.paper),
 		},
-		Data: map[string]string{
-			"eula.txt": "eula=true",
-		},
+		Data: data,
+	})
+}
+
+// ReconcilePaperInstance runs the server with the desired state. The Pod of the server is replaced once it differs
+// from the desired state, whether it is created by the operator or by the StatefulSet.
+func (r *Reconciler) ReconcilePaperInstance() Result {
+	if r.javaIncompatible {
+		// neither started nor replaced, nor is the Pod template of the StatefulSet updated, a running server keeps
+		// running its version, see ReconcileJavaCompatibility
+		return newSkippedResult()
 	}
 
-	err := ctrl.SetControllerReference(r.paper, cfg, r.scheme)
-	if err != nil {
-		return newFailedResult(err)
+	statefulSet := r.paper.Spec.GetWorkload() == papermciov1.WorkloadStatefulSet
+	if statefulSet {
+		if res := r.reconcilePaperStatefulSet(); res.Failed() {
+			return res
+		}
 	}
 
-	if err := r.client.Create(r.ctx, cfg); err != nil {
+	desiredPod, err := r.paperInstancePodWithAccess()
+	if err != nil {
 		return newFailedResult(err)
 	}
 
-	return newUpdatedResult()
-}
-
-func (r *Reconciler) ReconcilePaperInstance() Result {
 	// todo: recreate pod if unhealthy
 	existingPod := corev1.Pod{}
-	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: r.paper.Name}, &existingPod); err != nil {
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: instancePodName(r.paper)}, &existingPod); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return newFailedResult(err)
 		}
+	} else if existingPod.DeletionTimestamp != nil {
+		// instance is stopping, the Pod is recreated once gone
+		return newUpdatedResult()
 	} else if existingPod.Status.Phase == corev1.PodFailed {
 		// failure, recreate paper pod
+		r.paper.Status.Instance = instanceStatus(&existingPod)
+		r.paper.Status.Server = nil
+		message := fmt.Sprintf("Instance failed: %s, recreating", podFailureReason(&existingPod))
+		r.recorder.Event(r.paper, corev1.EventTypeWarning, eventReasonInstanceFailed, message)
+		if res := r.updateStatus(
+			condition(conditionTypeAvailable, metav1.ConditionFalse, reasonInstanceFailed, message),
+			condition(conditionTypeDegraded, metav1.ConditionTrue, reasonInstanceFailed, message),
+		); res.Failed() {
+			return res
+		}
 		return r.deletePaperInstance()
-	} else if existingPod.Status.Phase == corev1.PodRunning && !labels.Equals(existingPod.Labels, labelsForDesiredVersion(r.paper)) {
-		// upgrade, replace paper pod
-		return r.deletePaperInstance()
+	} else if existingPod.Status.Phase == corev1.PodRunning && existingPod.Labels[labelVersion] != r.paper.Status.DesiredState.Version.String() {
+		// upgrade, verify artifact before replacing paper pod
+		from, to := existingPod.Labels[labelVersion], r.paper.Status.DesiredState.Version.String()
+		if res := r.updateStatus(
+			condition(conditionTypeUpgrading, metav1.ConditionTrue, reasonVerifying, fmt.Sprintf("Verifying artifact before upgrading from %s to %s", from, to)),
+		); res.Failed() {
+			return res
+		}
+		if res := r.reconcileVerifierForDesiredVersion(); !res.Skipped() {
+			return res
+		}
+		message := fmt.Sprintf("Upgrading from %s to %s", from, to)
+		r.recorder.Event(r.paper, corev1.EventTypeNormal, eventReasonUpgrading, message)
+		return r.restartPaperInstance(&existingPod, reasonUpgrading, message, condition(conditionTypeUpgrading, metav1.ConditionTrue, reasonUpgrading, message))
+	} else if existingPod.Status.Phase == corev1.PodRunning && existingPod.Annotations[annotationConfigurationHash] != hashOf(configurationData(r.paper)) {
+		// configuration changed, restart paper pod
+		message := "Restarting instance, configuration changed"
+		r.recorder.Event(r.paper, corev1.EventTypeNormal, eventReasonRestarting, message)
+		return r.restartPaperInstance(&existingPod, reasonRestarting, message)
+	} else if existingPod.Status.Phase == corev1.PodRunning && existingPod.Annotations[annotationConfigFilesHash] != r.configFilesHash() {
+		// config files without reload command changed, restart paper pod
+		message := "Restarting instance, config files changed"
+		r.recorder.Event(r.paper, corev1.EventTypeNormal, eventReasonRestarting, message)
+		return r.restartPaperInstance(&existingPod, reasonRestarting, message)
+	} else if existingPod.Status.Phase == corev1.PodRunning && existingPod.Annotations[annotationSpecHash] != desiredPod.Annotations[annotationSpecHash] {
+		// pod spec changed, e.g. resources or jvm, replace paper pod
+		message := "Restarting instance, pod spec changed"
+		r.recorder.Event(r.paper, corev1.EventTypeNormal, eventReasonRestarting, message)
+		return r.restartPaperInstance(&existingPod, reasonRestarting, message)
 	} else if existingPod.Status.Phase != corev1.PodRunning {
 		// give it a moment
+		r.paper.Status.Instance = instanceStatus(&existingPod)
+		r.paper.Status.Server = nil
+		if res := r.updateStatus(
+			condition(conditionTypeAvailable, metav1.ConditionFalse, reasonStarting, "Waiting for the server to start"),
+			condition(conditionTypeProgressing, metav1.ConditionTrue, reasonStarting, "Waiting for the server to start"),
+		); res.Failed() {
+			return res
+		}
 		return newUpdatedResult()
 	} else {
 		// nothing to do, paper instance Pod exists
 		return newSkippedResult()
 	}
 
+	if !statefulSet {
+		err := ctrl.SetControllerReference(r.paper, desiredPod, r.scheme)
+		if err != nil {
+			return newFailedResult(err)
+		}
+
+		if err := r.client.Create(r.ctx, desiredPod); err != nil {
+			return newFailedResult(err)
+		}
+	}
+
+	// the StatefulSet creates its Pod itself, reported once
+	message := fmt.Sprintf("Starting instance with version %s", r.paper.Status.DesiredState.Version.String())
+	r.paper.Status.Instance = nil
+	r.paper.Status.Server = nil
+	res := r.updateStatus(
+		condition(conditionTypeAvailable, metav1.ConditionFalse, reasonStarting, message),
+		condition(conditionTypeProgressing, metav1.ConditionTrue, reasonStarting, message),
+	)
+	if res.Failed() {
+		return res
+	} else if res.Updated() || !statefulSet {
+		r.recorder.Event(r.paper, corev1.EventTypeNormal, eventReasonInstanceStarting, message)
+	}
+
+	return newUpdatedResult()
+}
+
+// restartPaperInstance reports the instance as unavailable for the given reason and stops it, its Pod is recreated
+// with the desired state.
+func (r *Reconciler) restartPaperInstance(pod *corev1.Pod, reason string, message string, conditions ...metav1.Condition) Result {
+	r.paper.Status.Server = nil
+	conditions = append(conditions,
+		condition(conditionTypeAvailable, metav1.ConditionFalse, reason, message),
+		condition(conditionTypeProgressing, metav1.ConditionTrue, reason, message),
+	)
+	if res := r.updateStatus(conditions...); res.Failed() {
+		return res
+	}
+
+	return r.stopPaperInstance(pod)
+}
+
+// paperInstancePodWithAccess builds the Pod running the server, annotated with the access it starts with.
+func (r *Reconciler) paperInstancePodWithAccess() (*corev1.Pod, error) {
+	pod, err := r.paperInstancePod()
+	if err != nil {
+		return nil, err
+	}
+	if r.access != nil {
+		// the files rendered from access are copied on start
+		applied, err := json.Marshal(r.access)
+		if err != nil {
+			return nil, err
+		}
+		pod.Annotations[annotationAppliedAccess] = string(applied)
+	}
+	return pod, nil
+}
+
+// paperInstancePod builds the Pod running the server for the desired version.
+func (r *Reconciler) paperInstancePod() (*corev1.Pod, error) {
+	configuration := configurationData(r.paper)
+
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "app-paper",
+			MountPath: "/app/paper",
+			ReadOnly:  true,
+		},
+		{
+			Name:      "app-data",
+			MountPath: "/app/data",
+		},
+		{
+			Name:      "configuration",
+			MountPath: "/app/data/eula.txt",
+			SubPath:   configurationEula,
+		},
+		{
+			Name:      "tmp",
+			MountPath: "/tmp",
+		},
+	}
+	startupProbe, readinessProbe, livenessProbe := probes(r.paper)
+
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.paper.Name,
 			Namespace: r.paper.Namespace,
-			Labels:    labelsForDesiredVersion(r.paper),
+			Labels:    labelsForServer(r.paper),
+			Annotations: map[string]string{
+				annotationConfigurationHash: hashOf(configuration),
+			},
 		},
 		Spec: corev1.PodSpec{
 			AutomountServiceAccountToken: pointer.Bool(false),
 			Containers: []corev1.Container{{
-				Name:       "paper",
-				Image:      r.imageForPaperInstance(r.paper),
-				Args:       []string{"/app/paper/paper.jar"},
-				WorkingDir: "/app/data",
-				VolumeMounts: []corev1.VolumeMount{
-					{
-						Name:      "app-paper",
-						MountPath: "/app/paper",
-						ReadOnly:  true,
-					},
-					{
-						Name:      "app-data",
-						MountPath: "/app/data",
-					},
-					{
-						Name:      "configuration",
-						MountPath: "/app/data/eula.txt",
-						SubPath:   "eula.txt",
-					},
-					{
-						Name:      "tmp",
-						MountPath: "/tmp",
-					},
-				},
-				//Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.}},
-				StartupProbe: &corev1.Probe{
-					ProbeHandler: corev1.ProbeHandler{
-						TCPSocket: &corev1.TCPSocketAction{
-							Port: intstr.FromInt(serverPort),
-						},
-					},
-					InitialDelaySeconds: 15,
-					PeriodSeconds:       5,
-					FailureThreshold:    60,
-				},
-				ReadinessProbe: &corev1.Probe{
-					ProbeHandler: corev1.ProbeHandler{
-						TCPSocket: &corev1.TCPSocketAction{
-							Port: intstr.FromInt(serverPort),
-						},
-					},
-					PeriodSeconds:    3,
-					FailureThreshold: 1,
-				},
-				LivenessProbe: &corev1.Probe{
-					ProbeHandler: corev1.ProbeHandler{
-						TCPSocket: &corev1.TCPSocketAction{
-							Port: intstr.FromInt(serverPort),
-						},
-					},
-					PeriodSeconds: 5,
-				},
+				Name:            containerName,
+				Image:           r.imageForPaperInstance(r.paper),
+				Command:         []string{"java"},
+				Args:            javaArgs(r.paper, "/app/paper/paper.jar"),
+				WorkingDir:      "/app/data",
+				VolumeMounts:    volumeMounts,
+				Resources:       r.paper.Spec.Resources,
+				StartupProbe:    startupProbe,
+				ReadinessProbe:  readinessProbe,
+				LivenessProbe:   livenessProbe,
 				SecurityContext: secureContainerSecurityContext(),
 			}},
 			// ServiceAccountName: p.Name,
-			RestartPolicy:   corev1.RestartPolicyAlways,
-			SecurityContext: securePodSecurityContext(),
+			InitContainers:                []corev1.Container{r.configureInitContainer()},
+			TerminationGracePeriodSeconds: pointer.Int64(terminationGracePeriodSeconds(r.paper)),
+			RestartPolicy:                 corev1.RestartPolicyAlways,
+			SecurityContext:               securePodSecurityContext(),
 			Volumes: []corev1.Volume{
 				{
 					Name: "app-paper",
@@ -464,82 +833,118 @@ func (r *Reconciler) ReconcilePaperInstance() Result {
 			},
 		},
 	}
+	if probeTypeOf(r.paper) == papermciov1.ProbeTypeStatus {
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, r.helperInitContainer())
+		pod.Spec.Volumes = append(pod.Spec.Volumes, helperVolumeSource())
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      helperVolume,
+			MountPath: helperMountPath,
+			ReadOnly:  true,
+		})
+	}
+	if r.paper.Status.Plugins != nil {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, r.pluginsVolumeSource())
+	}
+	if r.paper.Spec.GetWorkload() == papermciov1.WorkloadStatefulSet {
+		// claimed by the StatefulSet
+		volumes := pod.Spec.Volumes[:0]
+		for _, v := range pod.Spec.Volumes {
+			if v.Name != dataClaimTemplate {
+				volumes = append(volumes, v)
+			}
+		}
+		pod.Spec.Volumes = volumes
+	}
+	if r.configFiles != nil {
+		pod.Annotations[annotationConfigFilesHash] = r.configFiles.hash
+		pod.Spec.Volumes = append(pod.Spec.Volumes, r.configFilesVolumeSource())
+		if r.configFiles.reloadable {
+			pod.Spec.Containers = append(pod.Spec.Containers, r.syncFilesContainer())
+		}
+	}
 
-	err := ctrl.SetControllerReference(r.paper, pod, r.scheme)
+	pod, err := r.withPodTemplate(pod, true)
 	if err != nil {
-		return newFailedResult(err)
+		return nil, err
 	}
 
-	if err := r.client.Create(r.ctx, pod); err != nil {
-		return newFailedResult(err)
-	}
+	pod.Annotations[annotationSpecHash] = r.specHash(pod)
 
-	return newUpdatedResult()
+	return pod, nil
+}
+
+// specHash hashes the spec of the server Pod, along with the labels and annotations of the pod template, which are
+// applied by replacing the Pod as well. Without those, the hash stays the one of the spec alone, so servers are not
+// restarted for nothing.
+func (r *Reconciler) specHash(pod *corev1.Pod) string {
+	pt := r.paper.Spec.PodTemplate
+	if pt == nil || (len(pt.Labels) == 0 && len(pt.Annotations) == 0) {
+		return hashOf(pod.Spec)
+	}
+	return hashOf(struct {
+		Spec        corev1.PodSpec
+		Labels      map[string]string
+		Annotations map[string]string
+	}{pod.Spec, pt.Labels, pt.Annotations})
 }
 
 func (r *Reconciler) ReconcilePaperService() Result {
+	service := r.paperService()
+
 	existingService := corev1.Service{}
 	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: r.paper.Name}, &existingService); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return newFailedResult(err)
 		}
 	} else {
-		// nothing to do, paper instance Service exists
-		return newSkippedResult()
-	}
-
-	service := &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      r.paper.Name,
-			Namespace: r.paper.Namespace,
-			Labels:    labelsForPaperInstance(r.paper),
-		},
-		Spec: corev1.ServiceSpec{
-			Ports: []corev1.ServicePort{
-				{
-					Port:       serverPort,
-					TargetPort: intstr.FromInt(serverPort),
-				},
-			},
-			Selector: labelsForPaperInstance(r.paper),
-			Type:     corev1.ServiceTypeNodePort,
-		},
-	}
-
-	err := ctrl.SetControllerReference(r.paper, service, r.scheme)
-	if err != nil {
-		return newFailedResult(err)
-	}
-
-	if err := r.client.Create(r.ctx, service); err != nil {
-		return newFailedResult(err)
+		carryOverNodePorts(service, &existingService)
 	}
 
-	return newUpdatedResult()
+	return r.apply(service)
 }
 
 func (r *Reconciler) ReconcileOrphanObjects() Result {
 	logger := log.FromContext(r.ctx)
 
-	selectorString := fmt.Sprintf("app.kubernetes.io/instance=%s,app.kubernetes.io/version,app.kubernetes.io/version!=%s", r.paper.Name, r.paper.Status.DesiredState.Version.String())
-	selector, err := labels.Parse(selectorString)
-	if err != nil {
-		logger.Info("failed to parse selector", "string", selectorString, "err", err)
+	if r.javaIncompatible {
+		// objects of the version still running are in use
 		return newSkippedResult()
 	}
-	options := &client.ListOptions{
-		LabelSelector: selector,
-		Namespace:     r.paper.Namespace,
-	}
 
-	podList := &corev1.PodList{}
-	if err := r.client.List(r.ctx, podList, options); err != nil {
-		return newFailedResult(err)
+	// objects of other versions, and of other sets of plugins
+	selectorStrings := []string{
+		fmt.Sprintf("app.kubernetes.io/instance=%s,app.kubernetes.io/version,app.kubernetes.io/version!=%s", r.paper.Name, r.paper.Status.DesiredState.Version.String()),
+	}
+	if r.paper.Status.Plugins != nil {
+		selectorStrings = append(selectorStrings, fmt.Sprintf("app.kubernetes.io/instance=%s,%s,%s!=%s", r.paper.Name, labelPlugins, labelPlugins, r.paper.Status.Plugins.Hash))
+	} else {
+		selectorStrings = append(selectorStrings, fmt.Sprintf("app.kubernetes.io/instance=%s,%s", r.paper.Name, labelPlugins))
 	}
 
+	podList := &corev1.PodList{}
 	pvcList := &corev1.PersistentVolumeClaimList{}
-	if err := r.client.List(r.ctx, pvcList, options); err != nil {
-		return newFailedResult(err)
+	for _, selectorString := range selectorStrings {
+		selector, err := labels.Parse(selectorString)
+		if err != nil {
+			logger.Info("failed to parse selector", "string", selectorString, "err", err)
+			return newSkippedResult()
+		}
+		options := &client.ListOptions{
+			LabelSelector: selector,
+			Namespace:     r.paper.Namespace,
+		}
+
+		pods := &corev1.PodList{}
+		if err := r.client.List(r.ctx, pods, options); err != nil {
+			return newFailedResult(err)
+		}
+		podList.Items = append(podList.Items, pods.Items...)
+
+		pvcs := &corev1.PersistentVolumeClaimList{}
+		if err := r.client.List(r.ctx, pvcs, options); err != nil {
+			return newFailedResult(err)
+		}
+		pvcList.Items = append(pvcList.Items, pvcs.Items...)
 	}
 
 	if len(podList.Items) == 0 && len(pvcList.Items) == 0 {
@@ -549,24 +954,72 @@ func (r *Reconciler) ReconcileOrphanObjects() Result {
 	for _, pod := range podList.Items {
 		err := r.client.Delete(r.ctx, &pod)
 		logger.Info("orphan object deleted", "kind", pod.TypeMeta.Kind, "name", pod.Name, "err", err)
+		if err == nil {
+			r.recorder.Eventf(r.paper, corev1.EventTypeNormal, eventReasonOrphanDeleted, "Deleted orphan Pod %s", pod.Name)
+		}
 	}
 
 	for _, pvc := range pvcList.Items {
 		err := r.client.Delete(r.ctx, &pvc)
 		logger.Info("orphan object deleted", "kind", pvc.TypeMeta.Kind, "name", pvc.Name, "err", err)
+		if err == nil {
+			r.recorder.Eventf(r.paper, corev1.EventTypeNormal, eventReasonOrphanDeleted, "Deleted orphan PersistentVolumeClaim %s", pvc.Name)
+		}
 	}
 
 	return newUpdatedResult()
 }
 
 func (r *Reconciler) deletePaperInstance() Result {
-	err := r.client.Delete(r.ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: r.paper.Namespace, Name: r.paper.Name}})
+	err := r.client.Delete(r.ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: r.paper.Namespace, Name: instancePodName(r.paper)}})
 	if err != nil && !(apierrors.IsNotFound(err) || apierrors.IsGone(err)) {
 		return newFailedResult(err)
 	}
 	return newUpdatedResult()
 }
 
+// setDegraded reports the resource as degraded and records a warning event with the same reason. Failures concerning
+// the artifact also mark it as not ready.
+func (r *Reconciler) setDegraded(reason string, message string) Result {
+	r.recorder.Event(r.paper, corev1.EventTypeWarning, reason, message)
+
+	conditions := []metav1.Condition{condition(conditionTypeDegraded, metav1.ConditionTrue, reason, message)}
+	if reason == reasonChecksumMismatch || reason == reasonDownloadFailed {
+		conditions = append(conditions, condition(conditionTypeArtifactReady, metav1.ConditionFalse, reason, message))
+	}
+
+	return r.updateStatus(conditions...)
+}
+
+// failDegraded reports the resource as degraded for the error, see setDegraded. The error takes precedence over failing
+// to report it, it is recorded as event once only.
+func (r *Reconciler) failDegraded(reason string, message string, err error) Result {
+	_ = r.setDegraded(reason, message)
+	return newReportedFailedResult(err)
+}
+
+func terminatedExitCode(pod *corev1.Pod) int32 {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			return status.State.Terminated.ExitCode
+		}
+	}
+	return 0
+}
+
+// podFailureReason describes why the Pod failed, as far as known.
+func podFailureReason(pod *corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			return fmt.Sprintf("container %s terminated with exit code %d (%s)", status.Name, status.State.Terminated.ExitCode, status.State.Terminated.Reason)
+		}
+	}
+	if pod.Status.Reason != "" {
+		return pod.Status.Reason
+	}
+	return "unknown reason"
+}
+
 func secureContainerSecurityContext() *corev1.SecurityContext {
 	return &corev1.SecurityContext{
 		Capabilities: &corev1.Capabilities{
@@ -591,14 +1044,6 @@ func securePodSecurityContext() *corev1.PodSecurityContext {
 	}
 }
 
-func (r *Reconciler) imageForPaperDownloader(_ *papermciov1.Paper) string {
-	return imageDownloader
-}
-
-func (r *Reconciler) imageForPaperInstance(_ *papermciov1.Paper) string {
-	return imageServer
-}
-
 func buildObjectNameForVersion(name string, version papermciov1.Version) string {
 	return fmt.Sprintf("%s-%s", name, version.String())
 }
@@ -611,6 +1056,13 @@ func labelsForDesiredVersion(p *papermciov1.Paper) map[string]string {
 	}
 }
 
+// labelsForServer labels the Pod running the server.
+func labelsForServer(p *papermciov1.Paper) map[string]string {
+	labels := labelsForDesiredVersion(p)
+	labels[labelComponent] = componentServer
+	return labels
+}
+
 func labelsForPaperInstance(p *papermciov1.Paper) map[string]string {
 	return map[string]string{
 		labelName:     objectName,
@@ -1,5 +1,7 @@
 package reconciler
 
+import "time"
+
 type state int
 
 const (
@@ -11,12 +13,22 @@ const (
 type Result struct {
 	s state
 	e error
+
+	requeueAfter time.Duration
+
+	// reported failures were recorded as event already
+	reported bool
 }
 
 func newUpdatedResult() Result {
 	return Result{s: updated, e: nil}
 }
 
+// newRequeueResult is an updated result asking to reconcile again after the given duration at the latest.
+func newRequeueResult(after time.Duration) Result {
+	return Result{s: updated, e: nil, requeueAfter: after}
+}
+
 func newSkippedResult() Result {
 	return Result{s: skipped, e: nil}
 }
@@ -25,6 +37,12 @@ func newFailedResult(e error) Result {
 	return Result{s: failed, e: e}
 }
 
+// newReportedFailedResult is a failed result whose error was recorded as event already, e.g. along with the Degraded
+// condition.
+func newReportedFailedResult(e error) Result {
+	return Result{s: failed, e: e, reported: true}
+}
+
 func (r Result) Updated() bool {
 	return r.s == updated
 }
@@ -40,3 +58,13 @@ func (r Result) Failed() bool {
 func (r Result) GetError() error {
 	return r.e
 }
+
+// Reported tells whether the error of a failed result was recorded as event already.
+func (r Result) Reported() bool {
+	return r.reported
+}
+
+// RequeueAfter is the duration after which to reconcile again, zero if reconciliation is triggered by watches only.
+func (r Result) RequeueAfter() time.Duration {
+	return r.requeueAfter
+}
@@ -0,0 +1,108 @@
+package reconciler
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+	"github.com/baichinger/papermc-operator/pkg/papermc/slp"
+)
+
+const (
+	// ServerPingInterval is the interval the state of a running server is refreshed at, apart from the hourly
+	// reconciliation of the Paper resource.
+	ServerPingInterval = 1 * time.Minute
+
+	serverPingTimeout = 5 * time.Second
+)
+
+// NewServerPingReconciler creates a reconciler refreshing the server status of a Paper resource only, see
+// ReconcileServerStatus.
+func NewServerPingReconciler(client client.Client, ctx context.Context, paper *papermciov1.Paper) *Reconciler {
+	return &Reconciler{
+		client: client,
+		ctx:    ctx,
+		paper:  paper,
+
+		persistedStatus: paper.Status.DeepCopy(),
+	}
+}
+
+// ReconcileServerStatus refreshes the server status by a server list ping, apart from reconciling the Paper resource
+// as a whole. Only the server status is patched, the result asks to reconcile again once the next ping is due.
+func (r *Reconciler) ReconcileServerStatus() Result {
+	pod := corev1.Pod{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: instancePodName(r.paper)}, &pod); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+	}
+
+	patch := client.MergeFrom(r.paper.DeepCopy())
+	r.pingServer(&pod)
+	if !equality.Semantic.DeepEqual(r.persistedStatus.Server, r.paper.Status.Server) {
+		if err := r.client.Status().Patch(r.ctx, r.paper, patch); err != nil {
+			return newFailedResult(err)
+		}
+	}
+
+	next := ServerPingInterval
+	if server := r.paper.Status.Server; server != nil && server.LastPingTimestamp != nil {
+		if until := time.Until(server.LastPingTimestamp.Add(ServerPingInterval)); until > 0 {
+			next = until
+		}
+	}
+	return newRequeueResult(next)
+}
+
+// pingServer refreshes the server status by a server list ping, at most once per ServerPingInterval. A failed ping is
+// logged only, the server status is dropped once the server is not ready.
+func (r *Reconciler) pingServer(pod *corev1.Pod) {
+	logger := log.FromContext(r.ctx)
+
+	if !instanceStatus(pod).Ready || pod.Status.PodIP == "" {
+		r.paper.Status.Server = nil
+		return
+	}
+
+	if server := r.paper.Status.Server; server != nil && server.LastPingTimestamp != nil && time.Since(server.LastPingTimestamp.Time) < ServerPingInterval {
+		// nothing to do, server status is recent
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, serverPingTimeout)
+	defer cancel()
+
+	status, err := slp.Ping(ctx, net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(serverPort)))
+	if err != nil {
+		logger.Info("server list ping failed", "pod", pod.Name, "err", err)
+		return
+	}
+
+	var players []string
+	for _, player := range status.Players.Sample {
+		players = append(players, player.Name)
+	}
+
+	now := metav1.Now()
+	r.paper.Status.Server = &papermciov1.ServerStatus{
+		OnlinePlayers:       status.Players.Online,
+		MaxPlayers:          status.Players.Max,
+		Players:             players,
+		Version:             status.Version.Name,
+		Protocol:            status.Version.Protocol,
+		Motd:                status.Description,
+		LatencyMilliseconds: status.Latency.Milliseconds(),
+		LastPingTimestamp:   &now,
+	}
+}
@@ -0,0 +1,163 @@
+package reconciler
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+const (
+	servicePortName = "minecraft"
+	rconPortName    = "rcon"
+)
+
+// paperService builds the Service exposing the instance.
+func (r *Reconciler) paperService() *corev1.Service {
+	spec := r.paper.Spec.Service
+	if spec == nil {
+		spec = &papermciov1.ServiceSpec{}
+	}
+
+	serviceType := spec.Type
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeNodePort
+	}
+	withNodePorts := serviceType == corev1.ServiceTypeNodePort || serviceType == corev1.ServiceTypeLoadBalancer
+
+	port := int32(serverPort)
+	if spec.Port != nil {
+		port = *spec.Port
+	}
+
+	ports := []corev1.ServicePort{{
+		Protocol:   corev1.ProtocolTCP,
+		Port:       port,
+		TargetPort: intstr.FromInt(serverPort),
+	}}
+	if withNodePorts && spec.NodePort != nil {
+		ports[0].NodePort = *spec.NodePort
+	}
+	if len(spec.ExtraPorts) > 0 {
+		// names are required along with other ports only, the port stays as created before otherwise
+		ports[0].Name = servicePortName
+	}
+
+	for _, extraPort := range spec.ExtraPorts {
+		targetPort := extraPort.Port
+		if extraPort.TargetPort != nil {
+			targetPort = *extraPort.TargetPort
+		}
+		protocol := extraPort.Protocol
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		servicePort := corev1.ServicePort{
+			Name:       extraPort.Name,
+			Protocol:   protocol,
+			Port:       extraPort.Port,
+			TargetPort: intstr.FromInt(int(targetPort)),
+		}
+		if withNodePorts && extraPort.NodePort != nil {
+			servicePort.NodePort = *extraPort.NodePort
+		}
+		ports = append(ports, servicePort)
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.paper.Name,
+			Namespace:   r.paper.Namespace,
+			Labels:      labelsForPaperInstance(r.paper),
+			Annotations: spec.Annotations,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports:    ports,
+			Selector: selectorForServer(r.paper),
+			Type:     serviceType,
+		},
+	}
+
+	if serviceType == corev1.ServiceTypeLoadBalancer {
+		service.Spec.LoadBalancerIP = spec.LoadBalancerIP
+		service.Spec.LoadBalancerClass = spec.LoadBalancerClass
+	}
+	if withNodePorts {
+		service.Spec.ExternalTrafficPolicy = spec.ExternalTrafficPolicy
+	}
+
+	return service
+}
+
+// carryOverNodePorts keeps the node ports the cluster allocated for ports of the existing Service which the desired
+// Service leaves to the cluster, so players keep connecting to the same node port although the port entry changes,
+// e.g. once it is named along with extra ports.
+func carryOverNodePorts(desired *corev1.Service, existing *corev1.Service) {
+	if desired.Spec.Type != corev1.ServiceTypeNodePort && desired.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return
+	}
+
+	for i := range desired.Spec.Ports {
+		port := &desired.Spec.Ports[i]
+		if port.NodePort != 0 {
+			continue
+		}
+		for _, existingPort := range existing.Spec.Ports {
+			if existingPort.Port == port.Port && existingPort.Protocol == port.Protocol && existingPort.NodePort != 0 {
+				port.NodePort = existingPort.NodePort
+				break
+			}
+		}
+	}
+}
+
+// rconService builds the Service exposing RCON of the instance within the cluster only, it is never exposed
+// externally whatever the type of the Service of the server.
+func (r *Reconciler) rconService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rconServiceName(r.paper.Name),
+			Namespace: r.paper.Namespace,
+			Labels:    labelsForPaperInstance(r.paper),
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:       rconPortName,
+				Protocol:   corev1.ProtocolTCP,
+				Port:       rconPort,
+				TargetPort: intstr.FromInt(rconPort),
+			}},
+			Selector: selectorForServer(r.paper),
+			Type:     corev1.ServiceTypeClusterIP,
+		},
+	}
+}
+
+// headlessService builds the governing Service of the StatefulSet running the server, it gives the Pod a stable DNS
+// name. The Service exposing the instance cannot govern it, it is no headless Service.
+func (r *Reconciler) headlessService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      headlessServiceName(r.paper.Name),
+			Namespace: r.paper.Namespace,
+			Labels:    labelsForPaperInstance(r.paper),
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{{
+				Name:       servicePortName,
+				Protocol:   corev1.ProtocolTCP,
+				Port:       serverPort,
+				TargetPort: intstr.FromInt(serverPort),
+			}},
+			Selector: selectorForServer(r.paper),
+		},
+	}
+}
+
+func headlessServiceName(name string) string {
+	return fmt.Sprintf("%s-headless", name)
+}
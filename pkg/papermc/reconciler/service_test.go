@@ -0,0 +1,63 @@
+package reconciler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+func TestPaperServicePortName(t *testing.T) {
+	paper := &papermciov1.Paper{ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default"}}
+
+	service := (&Reconciler{paper: paper}).paperService()
+	assert.Len(t, service.Spec.Ports, 1)
+	assert.Empty(t, service.Spec.Ports[0].Name)
+
+	paper.Spec.Service = &papermciov1.ServiceSpec{ExtraPorts: []papermciov1.ServicePort{{Name: "query", Port: 25565, Protocol: corev1.ProtocolUDP}}}
+	service = (&Reconciler{paper: paper}).paperService()
+	assert.Len(t, service.Spec.Ports, 2)
+	assert.Equal(t, servicePortName, service.Spec.Ports[0].Name)
+	assert.Equal(t, "query", service.Spec.Ports[1].Name)
+}
+
+func TestCarryOverNodePorts(t *testing.T) {
+	existing := &corev1.Service{Spec: corev1.ServiceSpec{
+		Type: corev1.ServiceTypeNodePort,
+		Ports: []corev1.ServicePort{
+			{Protocol: corev1.ProtocolTCP, Port: 25565, NodePort: 31565},
+		},
+	}}
+
+	desired := &corev1.Service{Spec: corev1.ServiceSpec{
+		Type: corev1.ServiceTypeNodePort,
+		Ports: []corev1.ServicePort{
+			{Name: servicePortName, Protocol: corev1.ProtocolTCP, Port: 25565},
+			{Name: "query", Protocol: corev1.ProtocolUDP, Port: 25565},
+			{Name: "dynmap", Protocol: corev1.ProtocolTCP, Port: 8123, NodePort: 30123},
+		},
+	}}
+	carryOverNodePorts(desired, existing)
+	assert.Equal(t, int32(31565), desired.Spec.Ports[0].NodePort)
+	assert.Zero(t, desired.Spec.Ports[1].NodePort)
+	assert.Equal(t, int32(30123), desired.Spec.Ports[2].NodePort)
+
+	desired = &corev1.Service{Spec: corev1.ServiceSpec{
+		Type:  corev1.ServiceTypeClusterIP,
+		Ports: []corev1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 25565}},
+	}}
+	carryOverNodePorts(desired, existing)
+	assert.Zero(t, desired.Spec.Ports[0].NodePort)
+}
+
+func TestHeadlessService(t *testing.T) {
+	paper := &papermciov1.Paper{ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default"}}
+
+	service := (&Reconciler{paper: paper}).headlessService()
+	assert.Equal(t, "paper-headless", service.Name)
+	assert.Equal(t, corev1.ClusterIPNone, service.Spec.ClusterIP)
+	assert.Equal(t, selectorForServer(paper), service.Spec.Selector)
+}
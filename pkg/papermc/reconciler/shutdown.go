@@ -0,0 +1,214 @@
+package reconciler
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+const (
+	annotationStopAt          = "papermc.io/stop-at"
+	annotationStoppedAt       = "papermc.io/stopped-at"
+	annotationStoppedRestarts = "papermc.io/stopped-restarts"
+
+	// annotationAnnouncedSeconds are the remaining seconds of the countdown broadcast last
+	annotationAnnouncedSeconds = "papermc.io/announced-seconds"
+
+	defaultCountdownSeconds              = 10
+	defaultShutdownMessage               = "Server restarts in {seconds} seconds"
+	defaultTerminationGracePeriodSeconds = 60
+
+	stopPollInterval = 2 * time.Second
+)
+
+// stopPaperInstance stops the server gracefully before deleting its Pod. Players are warned repeatedly during the
+// configured countdown, see nextAnnouncement, then the worlds are saved and the server is stopped via RCON. The Pod is
+// deleted once the server exited, see awaitPaperInstanceExit. If RCON is unavailable, e.g. the server is not up yet,
+// the Pod is deleted right away.
+func (r *Reconciler) stopPaperInstance(pod *corev1.Pod) Result {
+	logger := log.FromContext(r.ctx)
+
+	if _, stopped := pod.Annotations[annotationStoppedAt]; stopped {
+		return r.awaitPaperInstanceExit(pod)
+	}
+
+	countdown := int(countdownSeconds(r.paper))
+
+	stopAt, scheduled := pod.Annotations[annotationStopAt]
+	if !scheduled && countdown > 0 {
+		return r.announceStop(pod, time.Now().Add(time.Duration(countdown)*time.Second).Truncate(time.Second), countdown)
+	}
+
+	if t, err := time.Parse(time.RFC3339, stopAt); scheduled && err == nil && time.Now().Before(t) {
+		announced, _ := strconv.Atoi(pod.Annotations[annotationAnnouncedSeconds])
+		remaining := int(math.Ceil(time.Until(t).Seconds()))
+		if next := nextAnnouncement(announced); next > 0 && remaining <= next {
+			return r.announceStop(pod, t, remaining)
+		}
+
+		// give it a moment, players are warned
+		return newRequeueResult(untilAnnouncement(t, announced))
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, rconTimeout)
+	defer cancel()
+
+	rc, err := r.rconClient(ctx, pod)
+	if err != nil {
+		logger.Info("rcon unavailable, deleting instance right away", "err", err)
+		return r.deletePaperInstance()
+	}
+	defer rc.Close()
+
+	if _, err := rc.Command(ctx, "save-all flush"); err != nil {
+		logger.Info("failed to save worlds", "err", err)
+	}
+	if _, err := rc.Command(ctx, "stop"); err != nil {
+		// the server may close the connection before answering
+		logger.Info("failed to stop server", "err", err)
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[annotationStoppedAt] = time.Now().UTC().Format(time.RFC3339)
+	pod.Annotations[annotationStoppedRestarts] = strconv.Itoa(int(serverRestartCount(pod)))
+	if err := r.client.Patch(r.ctx, pod, patch); err != nil {
+		return newFailedResult(err)
+	}
+
+	return newRequeueResult(stopPollInterval)
+}
+
+// announceStop broadcasts the remaining seconds until the server is stopped at the given time to players, and records
+// both on the Pod.
+func (r *Reconciler) announceStop(pod *corev1.Pod, stopAt time.Time, seconds int) Result {
+	logger := log.FromContext(r.ctx)
+
+	ctx, cancel := context.WithTimeout(r.ctx, rconTimeout)
+	defer cancel()
+
+	rc, err := r.rconClient(ctx, pod)
+	if err != nil {
+		logger.Info("rcon unavailable, deleting instance right away", "err", err)
+		return r.deletePaperInstance()
+	}
+	defer rc.Close()
+
+	if _, err := rc.Command(ctx, "say "+shutdownMessage(r.paper, seconds)); err != nil {
+		logger.Info("failed to warn players", "err", err)
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[annotationStopAt] = stopAt.UTC().Format(time.RFC3339)
+	pod.Annotations[annotationAnnouncedSeconds] = strconv.Itoa(seconds)
+	if err := r.client.Patch(r.ctx, pod, patch); err != nil {
+		return newFailedResult(err)
+	}
+
+	return newRequeueResult(untilAnnouncement(stopAt, seconds))
+}
+
+// nextAnnouncement is the remaining seconds of the countdown to broadcast next after the given ones: every minute, at
+// 30 and 10 seconds, and every second of the last 5 seconds. Zero if there is none left.
+func nextAnnouncement(seconds int) int {
+	switch {
+	case seconds > 60:
+		return (seconds - 1) / 60 * 60
+	case seconds > 30:
+		return 30
+	case seconds > 10:
+		return 10
+	case seconds > 5:
+		return 5
+	case seconds > 1:
+		return seconds - 1
+	default:
+		return 0
+	}
+}
+
+// untilAnnouncement is the time until the announcement following the given one is due, or until the server is stopped.
+func untilAnnouncement(stopAt time.Time, announced int) time.Duration {
+	due := stopAt
+	if next := nextAnnouncement(announced); next > 0 {
+		due = stopAt.Add(-time.Duration(next) * time.Second)
+	}
+	if d := time.Until(due); d > 0 {
+		return d
+	}
+	// due already, the requeue must not be dropped
+	return time.Millisecond
+}
+
+// awaitPaperInstanceExit deletes the Pod once the stopped server exited. The kubelet restarts the container right
+// after, so the exit is told apart by the restart count as well. If the server does not exit within the termination
+// grace period, the Pod is deleted anyway.
+func (r *Reconciler) awaitPaperInstanceExit(pod *corev1.Pod) Result {
+	logger := log.FromContext(r.ctx)
+
+	restarts, _ := strconv.Atoi(pod.Annotations[annotationStoppedRestarts])
+	status := serverContainerStatus(pod)
+	if status == nil || status.State.Terminated != nil || int(status.RestartCount) > restarts {
+		return r.deletePaperInstance()
+	}
+
+	grace := time.Duration(terminationGracePeriodSeconds(r.paper)) * time.Second
+	if t, err := time.Parse(time.RFC3339, pod.Annotations[annotationStoppedAt]); err != nil || time.Since(t) >= grace {
+		logger.Info("server did not exit within the termination grace period, deleting instance")
+		return r.deletePaperInstance()
+	}
+
+	// give it a moment, the server is saving the worlds
+	return newRequeueResult(stopPollInterval)
+}
+
+func serverContainerStatus(pod *corev1.Pod) *corev1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == containerName {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return nil
+}
+
+func serverRestartCount(pod *corev1.Pod) int32 {
+	if status := serverContainerStatus(pod); status != nil {
+		return status.RestartCount
+	}
+	return 0
+}
+
+func countdownSeconds(p *papermciov1.Paper) int32 {
+	if p.Spec.Shutdown != nil && p.Spec.Shutdown.CountdownSeconds != nil {
+		return *p.Spec.Shutdown.CountdownSeconds
+	}
+	return defaultCountdownSeconds
+}
+
+func shutdownMessage(p *papermciov1.Paper, seconds int) string {
+	message := defaultShutdownMessage
+	if p.Spec.Shutdown != nil && p.Spec.Shutdown.Message != "" {
+		message = p.Spec.Shutdown.Message
+	}
+	return strings.ReplaceAll(message, "{seconds}", strconv.Itoa(seconds))
+}
+
+func terminationGracePeriodSeconds(p *papermciov1.Paper) int64 {
+	if p.Spec.Shutdown != nil && p.Spec.Shutdown.TerminationGracePeriodSeconds != nil {
+		return *p.Spec.Shutdown.TerminationGracePeriodSeconds
+	}
+	return defaultTerminationGracePeriodSeconds
+}
@@ -0,0 +1,226 @@
+package reconciler
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+func TestNextAnnouncement(t *testing.T) {
+	var announced []int
+	for seconds := 150; seconds > 0; seconds = nextAnnouncement(seconds) {
+		announced = append(announced, seconds)
+	}
+	assert.Equal(t, []int{150, 120, 60, 30, 10, 5, 4, 3, 2, 1}, announced)
+}
+
+// newShutdownTest returns a reconciler of a Paper resource whose running server is stopped, and the Pod of the server.
+func newShutdownTest(t *testing.T, annotations map[string]string, status corev1.ContainerStatus) (*Reconciler, *corev1.Pod) {
+	paper := &papermciov1.Paper{
+		ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default"},
+		Spec: papermciov1.PaperSpec{
+			Shutdown: &papermciov1.ShutdownSpec{CountdownSeconds: pointer.Int32(30)},
+		},
+	}
+	status.Name = containerName
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: instancePodName(paper), Namespace: paper.Namespace, Annotations: annotations},
+		Status: corev1.PodStatus{
+			PodIP:             "127.0.0.1",
+			ContainerStatuses: []corev1.ContainerStatus{status},
+		},
+	}
+
+	c, scheme := newFakeClient(t, paper, pod, rconSecret(paper))
+	return NewPaperReconciler(c, c, scheme, record.NewFakeRecorder(10), context.Background(), paper, Options{}), pod
+}
+
+func running(restarts int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		RestartCount: restarts,
+		State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}
+}
+
+// getPod returns the Pod as persisted, nil if deleted.
+func getPod(t *testing.T, r *Reconciler, pod *corev1.Pod) *corev1.Pod {
+	persisted := &corev1.Pod{}
+	err := r.client.Get(r.ctx, client.ObjectKeyFromObject(pod), persisted)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	return persisted
+}
+
+func TestStopPaperInstanceStartsCountdown(t *testing.T) {
+	server := newFakeRconServer(t, nil)
+	r, pod := newShutdownTest(t, nil, running(0))
+
+	res := r.stopPaperInstance(pod)
+	require.NoError(t, res.GetError())
+	assert.True(t, res.Updated())
+	// the next announcement is due at 10 seconds left
+	assert.InDelta(t, 20*time.Second, res.RequeueAfter(), float64(time.Second))
+	assert.Equal(t, []string{"say Server restarts in 30 seconds"}, server.Commands())
+
+	persisted := getPod(t, r, pod)
+	require.NotNil(t, persisted)
+	assert.Equal(t, "30", persisted.Annotations[annotationAnnouncedSeconds])
+	stopAt, err := time.Parse(time.RFC3339, persisted.Annotations[annotationStopAt])
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), stopAt, time.Second)
+	assert.NotContains(t, persisted.Annotations, annotationStoppedAt)
+}
+
+func TestStopPaperInstanceAnnouncesCountdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining time.Duration
+		announced int
+		commands  []string
+	}{
+		{
+			name:      "not due",
+			remaining: 20 * time.Second,
+			announced: 30,
+		},
+		{
+			name:      "due",
+			remaining: 10 * time.Second,
+			announced: 30,
+			commands:  []string{"say Server restarts in 10 seconds"},
+		},
+		{
+			name:      "announced already",
+			remaining: 9 * time.Second,
+			announced: 10,
+		},
+		{
+			name:      "last seconds",
+			remaining: 3 * time.Second,
+			announced: 5,
+			commands:  []string{"say Server restarts in 3 seconds"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeRconServer(t, nil)
+			// the stop time is truncated to seconds and the remaining ones are rounded up, a test starting late in a
+			// second would see one second less remaining
+			if now := time.Now(); now.Nanosecond() > int(500*time.Millisecond) {
+				time.Sleep(now.Truncate(time.Second).Add(time.Second).Sub(now))
+			}
+			stopAt := time.Now().Truncate(time.Second).Add(tt.remaining)
+			r, pod := newShutdownTest(t, map[string]string{
+				annotationStopAt:           stopAt.UTC().Format(time.RFC3339),
+				annotationAnnouncedSeconds: strconv.Itoa(tt.announced),
+			}, running(0))
+
+			res := r.stopPaperInstance(pod)
+			require.NoError(t, res.GetError())
+			assert.Greater(t, res.RequeueAfter(), time.Duration(0))
+			assert.Equal(t, tt.commands, server.Commands())
+
+			persisted := getPod(t, r, pod)
+			require.NotNil(t, persisted)
+			assert.NotContains(t, persisted.Annotations, annotationStoppedAt)
+		})
+	}
+}
+
+func TestStopPaperInstanceStopsServer(t *testing.T) {
+	server := newFakeRconServer(t, nil)
+	r, pod := newShutdownTest(t, map[string]string{
+		annotationStopAt:           time.Now().Add(-time.Second).UTC().Format(time.RFC3339),
+		annotationAnnouncedSeconds: "1",
+	}, running(2))
+
+	res := r.stopPaperInstance(pod)
+	require.NoError(t, res.GetError())
+	assert.Equal(t, stopPollInterval, res.RequeueAfter())
+	assert.Equal(t, []string{"save-all flush", "stop"}, server.Commands())
+
+	persisted := getPod(t, r, pod)
+	require.NotNil(t, persisted)
+	assert.Contains(t, persisted.Annotations, annotationStoppedAt)
+	assert.Equal(t, "2", persisted.Annotations[annotationStoppedRestarts])
+}
+
+func TestStopPaperInstanceWithoutRcon(t *testing.T) {
+	r, pod := newShutdownTest(t, nil, running(0))
+	pod.Status.PodIP = ""
+
+	res := r.stopPaperInstance(pod)
+	require.NoError(t, res.GetError())
+	assert.Zero(t, res.RequeueAfter())
+	assert.Nil(t, getPod(t, r, pod))
+}
+
+func TestAwaitPaperInstanceExit(t *testing.T) {
+	tests := []struct {
+		name      string
+		stoppedAt time.Time
+		status    corev1.ContainerStatus
+		deleted   bool
+	}{
+		{
+			name:      "saving",
+			stoppedAt: time.Now(),
+			status:    running(2),
+		},
+		{
+			name:      "exited",
+			stoppedAt: time.Now(),
+			status: corev1.ContainerStatus{
+				RestartCount: 2,
+				State:        corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+			},
+			deleted: true,
+		},
+		{
+			name:      "restarted",
+			stoppedAt: time.Now(),
+			status:    running(3),
+			deleted:   true,
+		},
+		{
+			name:      "grace period exceeded",
+			stoppedAt: time.Now().Add(-defaultTerminationGracePeriodSeconds * time.Second),
+			status:    running(2),
+			deleted:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeRconServer(t, nil)
+			r, pod := newShutdownTest(t, map[string]string{
+				annotationStopAt:          tt.stoppedAt.UTC().Format(time.RFC3339),
+				annotationStoppedAt:       tt.stoppedAt.UTC().Format(time.RFC3339),
+				annotationStoppedRestarts: "2",
+			}, tt.status)
+
+			res := r.stopPaperInstance(pod)
+			require.NoError(t, res.GetError())
+			assert.Empty(t, server.Commands())
+			if tt.deleted {
+				assert.Nil(t, getPod(t, r, pod))
+				return
+			}
+			assert.Equal(t, stopPollInterval, res.RequeueAfter())
+			assert.NotNil(t, getPod(t, r, pod))
+		})
+	}
+}
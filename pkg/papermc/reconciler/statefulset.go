@@ -0,0 +1,142 @@
+package reconciler
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+// dataClaimTemplate is the name of the claim template of the world volume, the claim of the only replica is named
+// after it.
+const dataClaimTemplate = "app-data"
+
+// reconcilePaperStatefulSet applies the StatefulSet running the server. Its Pod template always follows the desired
+// state, the update strategy OnDelete leaves replacing the Pod to the operator, which stops the server gracefully
+// first, see ReconcilePaperInstance. A Pod rescheduled by Kubernetes in between starts with the desired state right
+// away.
+func (r *Reconciler) reconcilePaperStatefulSet() Result {
+	// the governing Service is expected to exist before the StatefulSet
+	if res := r.apply(r.headlessService()); res.Failed() {
+		return res
+	}
+
+	existingStatefulSet := appsv1.StatefulSet{}
+	found := true
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: r.paper.Name}, &existingStatefulSet); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+		found = false
+	}
+
+	statefulSet, err := r.paperStatefulSet()
+	if err != nil {
+		return newFailedResult(err)
+	}
+	if found {
+		// claim templates are immutable, the claim is expanded directly instead
+		statefulSet.Spec.VolumeClaimTemplates = existingStatefulSet.Spec.VolumeClaimTemplates
+		// the governing Service is immutable, StatefulSets created before the headless Service keep theirs
+		statefulSet.Spec.ServiceName = existingStatefulSet.Spec.ServiceName
+	}
+
+	return r.apply(statefulSet)
+}
+
+// paperStatefulSet builds the StatefulSet running the server, its Pod template matches the Pod of workload Pod.
+func (r *Reconciler) paperStatefulSet() (*appsv1.StatefulSet, error) {
+	pod, err := r.paperInstancePodWithAccess()
+	if err != nil {
+		return nil, err
+	}
+
+	var volume papermciov1.VolumeSpec
+	if r.paper.Spec.Storage != nil && r.paper.Spec.Storage.World != nil {
+		volume = *r.paper.Spec.Storage.World
+	}
+	size := *resource.NewScaledQuantity(1, resource.Giga)
+	if volume.Size != nil {
+		size = *volume.Size
+	}
+	accessModes := volume.AccessModes
+	if len(accessModes) == 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.paper.Name,
+			Namespace: r.paper.Namespace,
+			Labels:    labelsForPaperInstance(r.paper),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: pointer.Int32(1),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selectorForServer(r.paper),
+			},
+			ServiceName: headlessServiceName(r.paper.Name),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      pod.Labels,
+					Annotations: pod.Annotations,
+				},
+				Spec: pod.Spec,
+			},
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.OnDeleteStatefulSetStrategyType,
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   dataClaimTemplate,
+					Labels: labelsForPaperInstance(r.paper),
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      accessModes,
+					StorageClassName: volume.StorageClassName,
+					Selector:         volume.Selector,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: size,
+						},
+					},
+				},
+			}},
+			// the world is removed along with the Paper resource, like the claim of workload Pod
+			PersistentVolumeClaimRetentionPolicy: &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+				WhenDeleted: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+				WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+			},
+		},
+	}, nil
+}
+
+// instancePodName returns the name of the Pod running the server.
+func instancePodName(p *papermciov1.Paper) string {
+	if p.Spec.GetWorkload() == papermciov1.WorkloadStatefulSet {
+		return fmt.Sprintf("%s-0", p.Name)
+	}
+	return p.Name
+}
+
+// instanceClaimName returns the name of the claim of the world volume.
+func instanceClaimName(p *papermciov1.Paper) string {
+	if p.Spec.GetWorkload() == papermciov1.WorkloadStatefulSet {
+		return fmt.Sprintf("%s-%s", dataClaimTemplate, instancePodName(p))
+	}
+	return p.Name
+}
+
+// selectorForServer selects the Pod running the server, but none of the other Pods of the instance.
+func selectorForServer(p *papermciov1.Paper) map[string]string {
+	labels := labelsForPaperInstance(p)
+	labels[labelComponent] = componentServer
+	return labels
+}
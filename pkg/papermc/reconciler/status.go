@@ -0,0 +1,135 @@
+package reconciler
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+const (
+	conditionTypeAvailable     = "Available"
+	conditionTypeProgressing   = "Progressing"
+	conditionTypeDegraded      = "Degraded"
+	conditionTypeArtifactReady = "ArtifactReady"
+	conditionTypeUpgrading     = "Upgrading"
+	conditionTypeEula          = "EulaAccepted"
+
+	reasonReconciling      = "Reconciling"
+	reasonAccepted         = "Accepted"
+	reasonNotAccepted      = "NotAccepted"
+	reasonVersionSelected  = "VersionSelected"
+	reasonPending          = "Pending"
+	reasonDownloading      = "Downloading"
+	reasonDownloaded       = "Downloaded"
+	reasonDownloadFailed   = "DownloadFailed"
+	reasonChecksumMismatch = "ChecksumMismatch"
+	reasonApiError         = "PapermcApiError"
+	// reasonProfileResolutionFailed reports players of the access spec could not be resolved
+	reasonProfileResolutionFailed = "ProfileResolutionFailed"
+	// reasonPluginResolutionFailed and reasonPluginDownloadFailed report plugins could not be installed
+	reasonPluginResolutionFailed = "PluginResolutionFailed"
+	reasonPluginDownloadFailed   = "PluginDownloadFailed"
+	// reasonConfigFileFailed reports a config file could not be rendered
+	reasonConfigFileFailed = "ConfigFileFailed"
+	// reasonNoServerImage reports no server image is configured for the version of Java required
+	reasonNoServerImage = "NoServerImage"
+	// reasonJavaIncompatible and reasonJavaCheckFailed report the server image cannot run the desired version
+	reasonJavaIncompatible = "JavaIncompatible"
+	reasonJavaCheckFailed  = "JavaCheckFailed"
+	reasonVerifying        = "Verifying"
+	reasonUpgrading        = "Upgrading"
+	reasonUpToDate         = "UpToDate"
+	reasonStarting         = "Starting"
+	reasonRestarting       = "Restarting"
+	reasonRunning          = "Running"
+	reasonNotReady         = "NotReady"
+	reasonInstanceFailed   = "InstanceFailed"
+	reasonCrashLooping     = "CrashLooping"
+	reasonReconciled       = "Reconciled"
+	reasonAsExpected       = "AsExpected"
+
+	containerName = "paper"
+)
+
+// condition creates a condition of the given type.
+func condition(conditionType string, status metav1.ConditionStatus, reason string, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// updateStatus sets the given conditions and patches the status if it differs from the one last read or written. The
+// result is skipped if nothing changed.
+func (r *Reconciler) updateStatus(conditions ...metav1.Condition) Result {
+	for _, c := range conditions {
+		c.ObservedGeneration = r.paper.Generation
+		meta.SetStatusCondition(&r.paper.Status.Conditions, c)
+	}
+	r.paper.Status.ObservedGeneration = r.paper.Generation
+
+	// timestamps of the previous write must not count as change
+	r.paper.Status.UpdatedTimestamp = r.persistedStatus.UpdatedTimestamp
+	if equality.Semantic.DeepEqual(r.persistedStatus, &r.paper.Status) {
+		return newSkippedResult()
+	}
+
+	// only the fields changed are patched, the server status is patched by ReconcileServerStatus concurrently
+	base := r.paper.DeepCopy()
+	base.Status = *r.persistedStatus.DeepCopy()
+	now := metav1.Now()
+	r.paper.Status.UpdatedTimestamp = &now
+
+	if err := r.client.Status().Patch(r.ctx, r.paper, client.MergeFrom(base)); err != nil {
+		return newFailedResult(err)
+	}
+	r.persistedStatus = r.paper.Status.DeepCopy()
+
+	return newUpdatedResult()
+}
+
+// instanceStatus reports the state of the server Pod.
+func instanceStatus(pod *corev1.Pod) *papermciov1.InstanceStatus {
+	status := &papermciov1.InstanceStatus{
+		Phase: pod.Status.Phase,
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name != containerName {
+			continue
+		}
+
+		status.Ready = cs.Ready
+		status.RestartCount = cs.RestartCount
+
+		terminated := cs.State.Terminated
+		if terminated == nil {
+			terminated = cs.LastTerminationState.Terminated
+		}
+		if terminated != nil {
+			exitCode := terminated.ExitCode
+			finishedAt := terminated.FinishedAt
+			status.LastTerminationReason = terminated.Reason
+			status.LastTerminationExitCode = &exitCode
+			status.LastTerminationTimestamp = &finishedAt
+		}
+	}
+
+	return status
+}
+
+// crashLooping tells whether the server container of the Pod is backing off after repeated failures.
+func crashLooping(pod *corev1.Pod) bool {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == containerName && cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			return true
+		}
+	}
+	return false
+}
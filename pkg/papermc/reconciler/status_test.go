@@ -0,0 +1,62 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+// newFakeClient returns a client serving the given objects, the status of Paper and PaperCommand resources is a
+// subresource.
+func newFakeClient(t *testing.T, objs ...client.Object) (client.Client, *runtime.Scheme) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, papermciov1.AddToScheme(scheme))
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&papermciov1.Paper{}, &papermciov1.PaperCommand{}).
+		Build()
+	return c, scheme
+}
+
+func TestUpdateStatusKeepsServerStatus(t *testing.T) {
+	paper := &papermciov1.Paper{ObjectMeta: metav1.ObjectMeta{Name: "paper", Namespace: "default"}}
+	c, scheme := newFakeClient(t, paper)
+	ctx := context.Background()
+
+	read := &papermciov1.Paper{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(paper), read))
+	r := NewPaperReconciler(c, c, scheme, record.NewFakeRecorder(10), ctx, read, Options{})
+
+	// the server status is refreshed in between
+	pinged := &papermciov1.Paper{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(paper), pinged))
+	patch := client.MergeFrom(pinged.DeepCopy())
+	pinged.Status.Server = &papermciov1.ServerStatus{OnlinePlayers: 3, MaxPlayers: 20}
+	require.NoError(t, c.Status().Patch(ctx, pinged, patch))
+
+	res := r.updateStatus(condition(conditionTypeAvailable, metav1.ConditionTrue, reasonRunning, "running"))
+	require.NoError(t, res.GetError())
+	assert.True(t, res.Updated())
+
+	persisted := &papermciov1.Paper{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(paper), persisted))
+	assert.True(t, meta.IsStatusConditionTrue(persisted.Status.Conditions, conditionTypeAvailable))
+	require.NotNil(t, persisted.Status.Server)
+	assert.Equal(t, int32(3), persisted.Status.Server.OnlinePlayers)
+
+	assert.True(t, r.updateStatus().Skipped())
+}
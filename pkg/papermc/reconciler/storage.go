@@ -0,0 +1,124 @@
+package reconciler
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+)
+
+// reconcilePersistentVolumeClaim creates the claim if missing. An existing claim has its labels restored and is
+// expanded if the requested size grew, the remaining spec is immutable.
+func (r *Reconciler) reconcilePersistentVolumeClaim(name string, labels map[string]string, volume *papermciov1.VolumeSpec, defaultSize resource.Quantity) Result {
+	if volume == nil {
+		volume = &papermciov1.VolumeSpec{}
+	}
+
+	size := defaultSize
+	if volume.Size != nil {
+		size = *volume.Size
+	}
+
+	existingPvc := corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.paper.Namespace, Name: name}, &existingPvc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newFailedResult(err)
+		}
+	} else {
+		return r.updatePersistentVolumeClaim(&existingPvc, labels, size)
+	}
+
+	accessModes := volume.AccessModes
+	if len(accessModes) == 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: r.paper.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      accessModes,
+			StorageClassName: volume.StorageClassName,
+			Selector:         volume.Selector,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
+	}
+
+	err := ctrl.SetControllerReference(r.paper, pvc, r.scheme)
+	if err != nil {
+		return newFailedResult(err)
+	}
+
+	if err := r.client.Create(r.ctx, pvc); err != nil {
+		return newFailedResult(err)
+	}
+
+	return newUpdatedResult()
+}
+
+// updatePersistentVolumeClaim applies the labels and requested size of an existing claim.
+func (r *Reconciler) updatePersistentVolumeClaim(existing *corev1.PersistentVolumeClaim, labels map[string]string, size resource.Quantity) Result {
+	size, err := r.expandedSize(existing, size)
+	if err != nil {
+		return newFailedResult(err)
+	}
+
+	return r.apply(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      existing.Name,
+			Namespace: existing.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
+	})
+}
+
+// expandedSize returns the size to request for an existing claim. The claim grows only if its storage class allows
+// volume expansion, shrinking is not supported.
+func (r *Reconciler) expandedSize(pvc *corev1.PersistentVolumeClaim, size resource.Quantity) (resource.Quantity, error) {
+	logger := log.FromContext(r.ctx)
+
+	current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if size.Cmp(current) <= 0 {
+		return current, nil
+	}
+
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		logger.Info("pvc without storage class cannot be expanded", "name", pvc.Name)
+		return current, nil
+	}
+
+	storageClass := storagev1.StorageClass{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Name: *pvc.Spec.StorageClassName}, &storageClass); err != nil {
+		return current, err
+	}
+
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		logger.Info("storage class does not allow volume expansion", "name", pvc.Name, "storageClass", storageClass.Name)
+		return current, nil
+	}
+
+	logger.Info("expanding pvc", "name", pvc.Name, "from", current.String(), "to", size.String())
+	r.recorder.Eventf(r.paper, corev1.EventTypeNormal, eventReasonVolumeExpanding, "Expanding PersistentVolumeClaim %s from %s to %s", pvc.Name, current.String(), size.String())
+
+	return size, nil
+}
@@ -0,0 +1,160 @@
+package reconciler
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+	papermc "github.com/baichinger/papermc-operator/pkg/papermc/client"
+)
+
+var releaseVersionPattern = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+
+// selection is a version/build picked for a spec, along with a human-readable reason
+type selection struct {
+	version string
+	build   int
+	reason  string
+}
+
+// selectorForSpec summarizes the fields of a spec the selection depends on, a changed selector requires a new
+// selection.
+func selectorForSpec(spec *papermciov1.PaperSpec) string {
+	build := ""
+	if spec.Build != nil {
+		build = strconv.Itoa(*spec.Build)
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", spec.GetUpdatePolicy(), spec.Version, build, spec.GetChannel())
+}
+
+// selectBuild picks version and build according to the update policy and channel of the spec.
+func selectBuild(c papermc.Client, spec *papermciov1.PaperSpec) (*selection, error) {
+	policy := spec.GetUpdatePolicy()
+	channel := spec.GetChannel()
+
+	switch policy {
+	case papermciov1.UpdatePolicyPinned:
+		if spec.Build == nil {
+			return nil, fmt.Errorf("update policy %s requires a build", policy)
+		}
+		return &selection{
+			version: spec.Version,
+			build:   *spec.Build,
+			reason:  fmt.Sprintf("build %d of version %s is pinned", *spec.Build, spec.Version),
+		}, nil
+
+	case papermciov1.UpdatePolicyLatestBuild:
+		build, err := latestBuildOnChannel(c, spec.Version, channel)
+		if err != nil {
+			return nil, err
+		} else if build == 0 {
+			return nil, fmt.Errorf("no build of version %s on channel %s", spec.Version, channel)
+		}
+		return &selection{
+			version: spec.Version,
+			build:   build,
+			reason:  fmt.Sprintf("latest build of version %s on channel %s", spec.Version, channel),
+		}, nil
+
+	case papermciov1.UpdatePolicyLatestPatch, papermciov1.UpdatePolicyLatestRelease:
+		versions, err := c.GetVersions()
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := releaseVersions(versions)
+		scope := "any release"
+		if policy == papermciov1.UpdatePolicyLatestPatch {
+			minor := minorOf(spec.Version)
+			candidates = filterVersions(candidates, func(v string) bool { return minorOf(v) == minor })
+			scope = fmt.Sprintf("%s.x", minor)
+		}
+
+		// newest first, skipping versions without a build on the channel yet
+		for i := len(candidates) - 1; i >= 0; i-- {
+			build, err := latestBuildOnChannel(c, candidates[i], channel)
+			if err != nil {
+				return nil, err
+			} else if build == 0 {
+				continue
+			}
+			return &selection{
+				version: candidates[i],
+				build:   build,
+				reason:  fmt.Sprintf("latest build of latest version within %s on channel %s", scope, channel),
+			}, nil
+		}
+		return nil, fmt.Errorf("no version within %s has a build on channel %s", scope, channel)
+	}
+
+	return nil, fmt.Errorf("unknown update policy %s", policy)
+}
+
+// latestBuildOnChannel returns the latest build of a version on the channel, or 0 if there is none. The experimental
+// channel accepts builds of any channel of the API, the default channel only builds of its default channel.
+func latestBuildOnChannel(c papermc.Client, version string, channel papermciov1.Channel) (int, error) {
+	builds, err := c.GetBuildsForVersion(version)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := len(builds) - 1; i >= 0; i-- {
+		if channel == papermciov1.ChannelExperimental || builds[i].Channel == papermc.ChannelDefault {
+			return builds[i].Build, nil
+		}
+	}
+	return 0, nil
+}
+
+// releaseVersions filters pre-releases and release candidates, and sorts the remaining versions oldest first.
+func releaseVersions(versions []string) []string {
+	releases := filterVersions(versions, releaseVersionPattern.MatchString)
+	sort.SliceStable(releases, func(i, j int) bool {
+		return compareVersions(releases[i], releases[j]) < 0
+	})
+	return releases
+}
+
+func filterVersions(versions []string, keep func(string) bool) []string {
+	var filtered []string
+	for _, v := range versions {
+		if keep(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// minorOf returns the major.minor part of a version, e.g. 1.20 for 1.20.4.
+func minorOf(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// compareVersions compares dot-separated numeric versions, a missing part counts as 0.
+func compareVersions(a string, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var ai, bi int
+		if i < len(as) {
+			ai, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bi, _ = strconv.Atoi(bs[i])
+		}
+		if ai != bi {
+			if ai < bi {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
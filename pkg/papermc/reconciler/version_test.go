@@ -0,0 +1,194 @@
+package reconciler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+
+	papermciov1 "github.com/baichinger/papermc-operator/api/v1"
+	papermc "github.com/baichinger/papermc-operator/pkg/papermc/client"
+)
+
+// fakePapermcClient serves versions and their builds, oldest first, as the PaperMC API lists them.
+type fakePapermcClient struct {
+	versions []string
+	builds   map[string][]papermc.Build
+}
+
+func (c *fakePapermcClient) GetVersions() ([]string, error) {
+	return c.versions, nil
+}
+
+func (c *fakePapermcClient) GetBuildsForVersion(version string) ([]papermc.Build, error) {
+	builds, ok := c.builds[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown version %s", version)
+	}
+	return builds, nil
+}
+
+func (c *fakePapermcClient) GetBuildForVersion(version string) (int, error) {
+	builds, err := c.GetBuildsForVersion(version)
+	if err != nil || len(builds) == 0 {
+		return 0, err
+	}
+	return builds[len(builds)-1].Build, nil
+}
+
+func (c *fakePapermcClient) GetUrlForVersionBuildDownload(version string, build int) (string, error) {
+	return fmt.Sprintf("https://api.papermc.io/v2/projects/paper/versions/%[1]s/builds/%[2]d/downloads/paper-%[1]s-%[2]d.jar", version, build), nil
+}
+
+func (c *fakePapermcClient) GetSha256ForVersionBuildDownload(string, int) (string, error) {
+	return "", nil
+}
+
+func newFakePapermcClient() *fakePapermcClient {
+	return &fakePapermcClient{
+		versions: []string{"1.19.4", "1.20", "1.20.1", "1.20.2-pre1", "1.20.2-rc1", "1.20.2", "1.20.10", "1.21"},
+		builds: map[string][]papermc.Build{
+			"1.19.4":      {{Build: 549, Channel: papermc.ChannelDefault}, {Build: 550, Channel: papermc.ChannelDefault}},
+			"1.20":        {{Build: 17, Channel: papermc.ChannelDefault}},
+			"1.20.1":      {{Build: 195, Channel: papermc.ChannelDefault}, {Build: 196, Channel: papermc.ChannelExperimental}},
+			"1.20.2-pre1": {{Build: 1, Channel: papermc.ChannelExperimental}},
+			"1.20.2-rc1":  {{Build: 1, Channel: papermc.ChannelExperimental}},
+			"1.20.2":      {{Build: 318, Channel: papermc.ChannelDefault}},
+			"1.20.10":     {{Build: 3, Channel: papermc.ChannelExperimental}},
+			"1.21":        {{Build: 1, Channel: papermc.ChannelExperimental}},
+		},
+	}
+}
+
+func TestSelectBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    papermciov1.PaperSpec
+		version string
+		build   int
+		err     bool
+	}{
+		{
+			name:    "pinned",
+			spec:    papermciov1.PaperSpec{Version: "1.19.4", Build: pointer.Int(549)},
+			version: "1.19.4",
+			build:   549,
+		},
+		{
+			name: "pinned without build",
+			spec: papermciov1.PaperSpec{Version: "1.19.4", UpdatePolicy: papermciov1.UpdatePolicyPinned},
+			err:  true,
+		},
+		{
+			name:    "latest build",
+			spec:    papermciov1.PaperSpec{Version: "1.19.4"},
+			version: "1.19.4",
+			build:   550,
+		},
+		{
+			name:    "latest build of two-part version",
+			spec:    papermciov1.PaperSpec{Version: "1.20"},
+			version: "1.20",
+			build:   17,
+		},
+		{
+			name:    "latest build on experimental channel",
+			spec:    papermciov1.PaperSpec{Version: "1.20.1"},
+			version: "1.20.1",
+			build:   196,
+		},
+		{
+			name:    "latest build on default channel",
+			spec:    papermciov1.PaperSpec{Version: "1.20.1", Channel: papermciov1.ChannelDefault},
+			version: "1.20.1",
+			build:   195,
+		},
+		{
+			name: "latest build without build on channel",
+			spec: papermciov1.PaperSpec{Version: "1.21", Channel: papermciov1.ChannelDefault},
+			err:  true,
+		},
+		{
+			name:    "latest patch compares numerically",
+			spec:    papermciov1.PaperSpec{Version: "1.20.1", UpdatePolicy: papermciov1.UpdatePolicyLatestPatch},
+			version: "1.20.10",
+			build:   3,
+		},
+		{
+			name:    "latest patch of two-part version",
+			spec:    papermciov1.PaperSpec{Version: "1.20", UpdatePolicy: papermciov1.UpdatePolicyLatestPatch},
+			version: "1.20.10",
+			build:   3,
+		},
+		{
+			name:    "latest patch skips versions without build on channel",
+			spec:    papermciov1.PaperSpec{Version: "1.20", UpdatePolicy: papermciov1.UpdatePolicyLatestPatch, Channel: papermciov1.ChannelDefault},
+			version: "1.20.2",
+			build:   318,
+		},
+		{
+			name: "latest patch of unknown minor version",
+			spec: papermciov1.PaperSpec{Version: "1.18.2", UpdatePolicy: papermciov1.UpdatePolicyLatestPatch},
+			err:  true,
+		},
+		{
+			name:    "latest release",
+			spec:    papermciov1.PaperSpec{Version: "1.19.4", UpdatePolicy: papermciov1.UpdatePolicyLatestRelease},
+			version: "1.21",
+			build:   1,
+		},
+		{
+			name:    "latest release on default channel",
+			spec:    papermciov1.PaperSpec{Version: "1.19.4", UpdatePolicy: papermciov1.UpdatePolicyLatestRelease, Channel: papermciov1.ChannelDefault},
+			version: "1.20.2",
+			build:   318,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := selectBuild(newFakePapermcClient(), &tt.spec)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, s.version)
+			assert.Equal(t, tt.build, s.build)
+		})
+	}
+}
+
+func TestReleaseVersions(t *testing.T) {
+	assert.Equal(t,
+		[]string{"1.19.4", "1.20", "1.20.1", "1.20.2", "1.20.10", "1.21"},
+		releaseVersions([]string{"1.20.10", "1.21", "1.20.2-pre1", "1.19.4", "1.20", "1.20.2-rc1", "1.20.2", "1.20.1"}))
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "1.20.1", b: "1.20.1", want: 0},
+		{a: "1.20", b: "1.20.0", want: 0},
+		{a: "1.20", b: "1.20.1", want: -1},
+		{a: "1.20.2", b: "1.20.10", want: -1},
+		{a: "1.21", b: "1.20.10", want: 1},
+		{a: "2.0", b: "1.99.99", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s vs %s", tt.a, tt.b), func(t *testing.T) {
+			assert.Equal(t, tt.want, compareVersions(tt.a, tt.b))
+			assert.Equal(t, -tt.want, compareVersions(tt.b, tt.a))
+		})
+	}
+}
+
+func TestMinorOf(t *testing.T) {
+	assert.Equal(t, "1.20", minorOf("1.20"))
+	assert.Equal(t, "1.20", minorOf("1.20.4"))
+	assert.Equal(t, "1", minorOf("1"))
+}
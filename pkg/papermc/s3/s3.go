@@ -0,0 +1,299 @@
+// Package s3 implements a minimal client of the S3 API, sufficient to store backups on AWS or compatible storage like
+// MinIO. Requests are signed by AWS Signature Version 4, buckets are addressed path-style.
+package s3
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRegion is used to sign requests if no region is given, compatible storage usually accepts it.
+	DefaultRegion = "us-east-1"
+
+	amzDateFormat = "20060102T150405Z"
+	algorithm     = "AWS4-HMAC-SHA256"
+	service       = "s3"
+
+	// emptyPayloadHash is the hash of requests without body
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// Error is returned if the storage rejects a request.
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3 request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("s3 request failed with status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Object is an entry of a bucket.
+type Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// Client accesses a bucket. It is safe for concurrent use.
+type Client struct {
+	endpoint        *url.URL
+	bucket          string
+	region          string
+	accessKeyId     string
+	secretAccessKey string
+
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// New creates a client of the bucket at the given endpoint, e.g. https://s3.eu-central-1.amazonaws.com or
+// http://minio.minio:9000.
+func New(endpoint string, bucket string, region string, accessKeyId string, secretAccessKey string) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q, expected http(s)://host[:port]", endpoint)
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+	if region == "" {
+		region = DefaultRegion
+	}
+
+	return &Client{
+		endpoint:        &url.URL{Scheme: u.Scheme, Host: u.Host},
+		bucket:          bucket,
+		region:          region,
+		accessKeyId:     accessKeyId,
+		secretAccessKey: secretAccessKey,
+		httpClient:      http.DefaultClient,
+		now:             time.Now,
+	}, nil
+}
+
+// Put uploads the content of the reader as the object of the given key in a single request, the storage limits its
+// size, e.g. to 5GB on AWS. The content is read twice, to hash it and to upload it.
+func (c *Client) Put(ctx context.Context, key string, content io.ReadSeeker) error {
+	hash := sha256.New()
+	size, err := io.Copy(hash, content)
+	if err != nil {
+		return err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.objectUrl(key).String(), io.NopCloser(content))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+
+	res, err := c.do(req, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// List returns the objects whose key starts with the given prefix, ordered by key.
+func (c *Client) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u := c.bucketUrl()
+		u.RawQuery = canonicalQuery(query)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.do(req, emptyPayloadHash)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Contents              []Object `xml:"Contents"`
+			IsTruncated           bool     `xml:"IsTruncated"`
+			NextContinuationToken string   `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		_ = res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid list response: %w", err)
+		}
+
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Delete removes the object of the given key, a missing object is not an error.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.objectUrl(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.do(req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// do signs and sends the request, responses other than 2xx are returned as Error.
+func (c *Client) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	sign(req, c.accessKeyId, c.secretAccessKey, c.region, c.now(), payloadHash)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res, nil
+	}
+	defer res.Body.Close()
+
+	e := &Error{}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+	_ = xml.Unmarshal(body, e)
+	e.StatusCode = res.StatusCode
+	return nil, e
+}
+
+func (c *Client) bucketUrl() *url.URL {
+	u := *c.endpoint
+	u.Path = "/" + c.bucket
+	u.RawPath = "/" + uriEncode(c.bucket, false)
+	return &u
+}
+
+func (c *Client) objectUrl(key string) *url.URL {
+	u := *c.endpoint
+	u.Path = "/" + c.bucket + "/" + key
+	u.RawPath = "/" + uriEncode(c.bucket, false) + "/" + uriEncode(key, true)
+	return &u
+}
+
+// sign adds the headers of AWS Signature Version 4 to the request. Besides the host, all headers set are signed.
+func sign(req *http.Request, accessKeyId string, secretAccessKey string, region string, now time.Time, payloadHash string) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		if strings.EqualFold(name, "Authorization") {
+			continue
+		}
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := strings.Builder{}
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{algorithm, amzDate, scope, hexSha256([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSha256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSha256(key, region)
+	key = hmacSha256(key, service)
+	key = hmacSha256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s", algorithm, accessKeyId, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes the query sorted by key, as required by the signature.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, false)+"="+uriEncode(v, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but the unreserved characters of RFC 3986, and slashes if requested.
+func uriEncode(s string, keepSlash bool) string {
+	b := strings.Builder{}
+	for _, c := range []byte(s) {
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' || (keepSlash && c == '/') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSha256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package slp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// maxPacketLength limits the size of packets read, a status response is far smaller
+	maxPacketLength = 1 << 21
+
+	// maxStringLength is the maximum length of a string in characters, as defined by the protocol
+	maxStringLength = 32767
+)
+
+var errVarIntTooLong = errors.New("varint too long")
+
+// appendVarInt appends the value in the variable length encoding of the protocol.
+func appendVarInt(buf []byte, value int32) []byte {
+	v := uint32(value)
+	for {
+		if v&^0x7f == 0 {
+			return append(buf, byte(v))
+		}
+		buf = append(buf, byte(v&0x7f|0x80))
+		v >>= 7
+	}
+}
+
+// readVarInt reads a value in the variable length encoding of the protocol.
+func readVarInt(r io.ByteReader) (int32, error) {
+	var value uint32
+	for i := 0; i < 5; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(value), nil
+		}
+	}
+	return 0, errVarIntTooLong
+}
+
+// appendString appends the string prefixed by its length in bytes.
+func appendString(buf []byte, s string) []byte {
+	buf = appendVarInt(buf, int32(len(s)))
+	return append(buf, s...)
+}
+
+// readString reads a string prefixed by its length in bytes.
+func readString(r *bytes.Reader) (string, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return "", err
+	}
+	if length < 0 || int(length) > maxStringLength*4 || int(length) > r.Len() {
+		return "", fmt.Errorf("invalid string length %d", length)
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// writePacket writes a packet with the given id and data, prefixed by its length.
+func writePacket(w io.Writer, id int32, data []byte) error {
+	payload := appendVarInt(nil, id)
+	payload = append(payload, data...)
+
+	packet := appendVarInt(make([]byte, 0, len(payload)+5), int32(len(payload)))
+	packet = append(packet, payload...)
+
+	_, err := w.Write(packet)
+	return err
+}
+
+// readPacket reads a packet, returning its id and a reader for its data.
+func readPacket(r *bufio.Reader) (int32, *bytes.Reader, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if length <= 0 || length > maxPacketLength {
+		return 0, nil, fmt.Errorf("invalid packet length %d", length)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	data := bytes.NewReader(payload)
+	id, err := readVarInt(data)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return id, data, nil
+}
+
+// handshake builds the data of the handshake packet, announcing the next state.
+func handshake(protocolVersion int32, host string, port uint16, nextState int32) []byte {
+	data := appendVarInt(nil, protocolVersion)
+	data = appendString(data, host)
+	data = binary.BigEndian.AppendUint16(data, port)
+	return appendVarInt(data, nextState)
+}
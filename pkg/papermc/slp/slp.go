@@ -0,0 +1,233 @@
+// Package slp implements the client side of the Minecraft Server List Ping protocol, which reports players, version
+// and MOTD of a running server.
+package slp
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	packetIdHandshake = 0x00
+	packetIdStatus    = 0x00
+	packetIdPing      = 0x01
+
+	stateStatus = 1
+
+	// protocolVersionAny lets the server report its own protocol version
+	protocolVersionAny = -1
+)
+
+// Status is the status reported by a server.
+type Status struct {
+	Version Version `json:"version"`
+	Players Players `json:"players"`
+
+	// Description is the MOTD as plain text, without formatting.
+	Description string `json:"-"`
+
+	// Latency is the round trip time of the ping following the status request.
+	Latency time.Duration `json:"-"`
+}
+
+// Version is the version of the server.
+type Version struct {
+	Name     string `json:"name"`
+	Protocol int32  `json:"protocol"`
+}
+
+// Players reports the players online. The sample is chosen by the server and may be incomplete.
+type Players struct {
+	Max    int32    `json:"max"`
+	Online int32    `json:"online"`
+	Sample []Player `json:"sample,omitempty"`
+}
+
+// Player is a player online.
+type Player struct {
+	Name string `json:"name"`
+	Id   string `json:"id"`
+}
+
+// Ping requests the status of the server at the given address, host and port. The request is canceled once the
+// context is done.
+func Ping(ctx context.Context, address string) (*Status, error) {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q: %w", portString, err)
+	}
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// unblock pending reads and writes
+			_ = conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	status, err := requestStatus(conn, host, uint16(port))
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return status, err
+}
+
+func requestStatus(conn net.Conn, host string, port uint16) (*Status, error) {
+	r := bufio.NewReader(conn)
+
+	if err := writePacket(conn, packetIdHandshake, handshake(protocolVersionAny, host, port, stateStatus)); err != nil {
+		return nil, err
+	}
+	if err := writePacket(conn, packetIdStatus, nil); err != nil {
+		return nil, err
+	}
+
+	id, data, err := readPacket(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status response: %w", err)
+	}
+	if id != packetIdStatus {
+		return nil, fmt.Errorf("unexpected packet id %d, expected status response", id)
+	}
+	response, err := readString(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status response: %w", err)
+	}
+
+	status, err := parseStatus([]byte(response))
+	if err != nil {
+		return nil, err
+	}
+
+	payload := time.Now().UnixNano()
+	sent := time.Now()
+	if err := writePacket(conn, packetIdPing, binary.BigEndian.AppendUint64(nil, uint64(payload))); err != nil {
+		return nil, err
+	}
+
+	id, data, err = readPacket(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pong: %w", err)
+	}
+	status.Latency = time.Since(sent)
+	var pong int64
+	if id != packetIdPing || binary.Read(data, binary.BigEndian, &pong) != nil || pong != payload {
+		return nil, fmt.Errorf("invalid pong")
+	}
+
+	return status, nil
+}
+
+func parseStatus(response []byte) (*Status, error) {
+	status := struct {
+		Status
+		Description json.RawMessage `json:"description"`
+	}{}
+	if err := json.Unmarshal(response, &status); err != nil {
+		return nil, fmt.Errorf("invalid status response: %w", err)
+	}
+
+	description, err := plainText(status.Description)
+	if err != nil {
+		return nil, fmt.Errorf("invalid description: %w", err)
+	}
+	status.Status.Description = description
+
+	return &status.Status, nil
+}
+
+// plainText flattens a chat component, which is either a string, an object with text and extra components, or a list
+// of components. Legacy formatting codes are removed.
+func plainText(component json.RawMessage) (string, error) {
+	var sb strings.Builder
+	if err := appendText(&sb, component); err != nil {
+		return "", err
+	}
+
+	return stripFormatting(sb.String()), nil
+}
+
+func appendText(sb *strings.Builder, component json.RawMessage) error {
+	trimmed := strings.TrimSpace(string(component))
+	if trimmed == "" || trimmed == "null" {
+		return nil
+	}
+
+	switch trimmed[0] {
+	case '"':
+		var text string
+		if err := json.Unmarshal(component, &text); err != nil {
+			return err
+		}
+		sb.WriteString(text)
+	case '[':
+		var components []json.RawMessage
+		if err := json.Unmarshal(component, &components); err != nil {
+			return err
+		}
+		for _, c := range components {
+			if err := appendText(sb, c); err != nil {
+				return err
+			}
+		}
+	default:
+		var object struct {
+			Text  string            `json:"text"`
+			Extra []json.RawMessage `json:"extra"`
+		}
+		if err := json.Unmarshal(component, &object); err != nil {
+			return err
+		}
+		sb.WriteString(object.Text)
+		for _, c := range object.Extra {
+			if err := appendText(sb, c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stripFormatting removes legacy formatting codes, a section sign followed by a single character.
+func stripFormatting(s string) string {
+	var sb strings.Builder
+	skip := false
+	for _, r := range s {
+		if skip {
+			skip = false
+			continue
+		}
+		if r == '§' {
+			skip = true
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,141 @@
+package slp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const statusResponse = `{
+	"version": {"name": "Paper 1.19.2", "protocol": 760},
+	"players": {"max": 20, "online": 2, "sample": [{"name": "alice", "id": "4566e69f-c907-48ee-8d71-d7ba5aa00d20"}, {"name": "bob", "id": "0d5b0c5e-7f4b-4a2e-9bd6-3bd5c4b7a8d1"}]},
+	"description": {"text": "§aA PaperMC server", "extra": [" on ", {"text": "§lKubernetes"}]}
+}`
+
+// newFakeServer serves the status protocol on a local port and returns its address. The status request is answered
+// with the given response, if empty the connection is left idle.
+func newFakeServer(t *testing.T, response string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFake(conn, response)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func serveFake(conn net.Conn, response string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	// handshake
+	if id, data, err := readPacket(r); err != nil || id != packetIdHandshake {
+		return
+	} else if _, err := readVarInt(data); err != nil {
+		return
+	} else if _, err := readString(data); err != nil {
+		return
+	}
+
+	// status request
+	if id, _, err := readPacket(r); err != nil || id != packetIdStatus {
+		return
+	}
+	if response == "" {
+		_, _ = r.ReadByte()
+		return
+	}
+	if err := writePacket(conn, packetIdStatus, appendString(nil, response)); err != nil {
+		return
+	}
+
+	// ping
+	id, data, err := readPacket(r)
+	if err != nil || id != packetIdPing {
+		return
+	}
+	var payload int64
+	if err := binary.Read(data, binary.BigEndian, &payload); err != nil {
+		return
+	}
+	_ = writePacket(conn, packetIdPing, binary.BigEndian.AppendUint64(nil, uint64(payload)))
+}
+
+func TestPing(t *testing.T) {
+	address := newFakeServer(t, statusResponse)
+
+	status, err := Ping(context.TODO(), address)
+
+	require.NoError(t, err)
+	assert.Equal(t, Version{Name: "Paper 1.19.2", Protocol: 760}, status.Version)
+	assert.Equal(t, int32(20), status.Players.Max)
+	assert.Equal(t, int32(2), status.Players.Online)
+	assert.Equal(t, []Player{{"alice", "4566e69f-c907-48ee-8d71-d7ba5aa00d20"}, {"bob", "0d5b0c5e-7f4b-4a2e-9bd6-3bd5c4b7a8d1"}}, status.Players.Sample)
+	assert.Equal(t, "A PaperMC server on Kubernetes", status.Description)
+	assert.Greater(t, status.Latency, time.Duration(0))
+}
+
+func TestPingDescriptionString(t *testing.T) {
+	address := newFakeServer(t, `{"version":{"name":"1.19.2","protocol":760},"players":{"max":20,"online":0},"description":"A §cPaperMC§r server"}`)
+
+	status, err := Ping(context.TODO(), address)
+
+	require.NoError(t, err)
+	assert.Equal(t, "A PaperMC server", status.Description)
+	assert.Empty(t, status.Players.Sample)
+}
+
+func TestPingInvalidResponse(t *testing.T) {
+	address := newFakeServer(t, `not json`)
+
+	_, err := Ping(context.TODO(), address)
+
+	assert.Error(t, err)
+}
+
+func TestPingTimeout(t *testing.T) {
+	address := newFakeServer(t, "")
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	_, err := Ping(ctx, address)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestPingRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	_, err = Ping(context.TODO(), address)
+
+	assert.Error(t, err)
+}
+
+func TestVarInt(t *testing.T) {
+	for _, value := range []int32{0, 1, 127, 128, 255, 25565, 2097151, 2147483647, -1, -2147483648} {
+		buf := appendVarInt(nil, value)
+		assert.LessOrEqual(t, len(buf), 5)
+
+		decoded, err := readVarInt(bytes.NewReader(buf))
+		require.NoError(t, err)
+		assert.Equal(t, value, decoded)
+	}
+}